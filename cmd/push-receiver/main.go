@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/internal/atomicfile"
 )
 
 func main() {
@@ -122,6 +123,23 @@ func main() {
 			log.Fatalf("Failed to parse credentials: %v", err)
 		}
 
+		// Replace RSA keys saved by older versions with P-256 keys
+		migrated, err := creds.MigrateKeys()
+		if err != nil {
+			log.Fatalf("Failed to migrate keys: %v", err)
+		}
+		if migrated {
+			data, err := json.MarshalIndent(creds, "", "  ")
+			if err != nil {
+				log.Fatalf("Failed to marshal credentials: %v", err)
+			}
+			// Losing the file would lose the registration, so replace it atomically
+			if err := atomicfile.WriteFile(*listenCredsFile, data, 0600); err != nil {
+				log.Fatalf("Failed to write credentials file: %v", err)
+			}
+			fmt.Println("Replaced legacy RSA keys with P-256 keys")
+		}
+
 		// Parse persistent IDs if provided
 		var persistentIDs []string
 		if *listenPersistentIDs != "" {
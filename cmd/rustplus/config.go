@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/internal/atomicfile"
 )
 
 // Config represents the configuration for the Rust+ CLI
@@ -37,5 +38,5 @@ func saveConfig(configFile string, config Config) error {
 		return err
 	}
 
-	return os.WriteFile(configFile, data, 0600)
+	return atomicfile.WriteFile(configFile, data, 0600)
 }
@@ -24,6 +24,18 @@ func fcmListen(configFile string) error {
 		return fmt.Errorf("FCM Credentials missing. Please run `fcm-register` first")
 	}
 
+	// Replace RSA keys saved by older versions with P-256 keys
+	migrated, err := config.FCMCredentials.MigrateKeys()
+	if err != nil {
+		return fmt.Errorf("failed to migrate FCM keys: %w", err)
+	}
+	if migrated {
+		if err := saveConfig(configFile, config); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Println("Replaced legacy RSA FCM keys with P-256 keys")
+	}
+
 	fmt.Println("Listening for FCM Notifications")
 
 	// Start listening for notifications
@@ -28,15 +28,10 @@ func main() {
 	client := rustplus.NewClient(serverIP, serverPort, playerID, playerToken, false)
 
 	// Set up entity changed handler
-	client.AddMessageHandler(func(msg *proto.AppMessage) bool {
-		if msg.Broadcast != nil && msg.Broadcast.EntityChanged != nil {
-			entityChanged := msg.Broadcast.EntityChanged
-			fmt.Printf("Entity %d is now %s\n",
-				entityChanged.GetEntityId(),
-				map[bool]string{true: "active", false: "inactive"}[entityChanged.GetPayload().GetValue()])
-			return true
-		}
-		return false
+	client.OnEntityChanged(uint32(entityID), func(entityChanged *proto.AppEntityChanged) {
+		fmt.Printf("Entity %d is now %s\n",
+			entityChanged.GetEntityId(),
+			map[bool]string{true: "active", false: "inactive"}[entityChanged.GetPayload().GetValue()])
 	})
 
 	// Connect to the server
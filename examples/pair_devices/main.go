@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/pairing"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println("Usage: pair_devices <store_file> [credentials_file]")
+		os.Exit(1)
+	}
+
+	// Open the store holding pairings, credentials and persistent IDs
+	store, err := pairing.OpenJSONStore(os.Args[1])
+	if err != nil {
+		log.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	// Import the FCM credentials on the first run
+	if len(os.Args) > 2 {
+		data, err := os.ReadFile(os.Args[2])
+		if err != nil {
+			log.Fatalf("Failed to read credentials: %v", err)
+		}
+		var credentials fcm.Credentials
+		if err := json.Unmarshal(data, &credentials); err != nil {
+			log.Fatalf("Failed to parse credentials: %v", err)
+		}
+		if err := store.SaveCredentials(credentials); err != nil {
+			log.Fatalf("Failed to save credentials: %v", err)
+		}
+	}
+
+	credentials, ok, err := store.Credentials()
+	if err != nil || !ok {
+		log.Fatalf("No FCM credentials in store, pass a credentials file")
+	}
+	if migrated, err := credentials.MigrateKeys(); err != nil {
+		log.Fatalf("Failed to migrate keys: %v", err)
+	} else if migrated {
+		if err := store.SaveCredentials(credentials); err != nil {
+			log.Fatalf("Failed to save credentials: %v", err)
+		}
+	}
+	persistentIDs, err := store.PersistentIDs()
+	if err != nil {
+		log.Fatalf("Failed to load persistent IDs: %v", err)
+	}
+
+	// Load servers paired before and reconnect to them
+	registry, err := pairing.NewRegistry(store)
+	if err != nil {
+		log.Fatalf("Failed to open registry: %v", err)
+	}
+
+	manager := rustplus.NewManager()
+	defer manager.Close()
+
+	pipeline := pairing.NewPipeline(registry, manager)
+	if err := pipeline.Restore(); err != nil {
+		log.Fatalf("Failed to restore servers: %v", err)
+	}
+
+	// Stop on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	notifications, err := fcm.ListenContext(ctx, fcm.ListenConfig{Credentials: credentials, PersistentIds: persistentIDs})
+	if err != nil {
+		log.Fatalf("Failed to start listening: %v", err)
+	}
+
+	// Print each pairing
+	go func() {
+		for paired := range pipeline.Events() {
+			entity := paired.Notification.Entity
+			if entity == nil {
+				fmt.Printf("Paired with server %s (%s)\n", paired.Notification.Name, paired.Server)
+				continue
+			}
+
+			fmt.Printf("Paired %s %q (%d) on %s\n", entity.Type, entity.Name, entity.ID, paired.Server)
+		}
+	}()
+
+	fmt.Println("Pair servers and devices in game, press Ctrl+C to exit")
+	if err := pipeline.Run(ctx, notifications); err != nil && ctx.Err() == nil {
+		log.Fatalf("Pipeline failed: %v", err)
+	}
+}
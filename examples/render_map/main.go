@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/mapview"
+)
+
+func main() {
+	if len(os.Args) < 5 {
+		fmt.Println("Usage: render_map <server_ip> <server_port> <player_id> <player_token> [output_file]")
+		os.Exit(1)
+	}
+
+	serverIP := os.Args[1]
+	serverPort, _ := strconv.Atoi(os.Args[2])
+	playerID, _ := strconv.ParseUint(os.Args[3], 10, 64)
+	playerToken, _ := strconv.Atoi(os.Args[4])
+
+	outputFile := "map.png"
+	if len(os.Args) > 5 {
+		outputFile = os.Args[5]
+	}
+
+	// Create a new client
+	client := rustplus.NewClient(serverIP, serverPort, playerID, playerToken, false)
+
+	// Connect to the server
+	if err := client.Connect(); err != nil {
+		log.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// Get the map size and image
+	info, err := client.GetInfo()
+	if err != nil {
+		log.Fatalf("Failed to get info: %v", err)
+	}
+
+	fmt.Println("Getting map...")
+	mapData, err := client.GetMap()
+	if err != nil {
+		log.Fatalf("Failed to get map: %v", err)
+	}
+
+	m, err := mapview.New(mapData, info.GetMapSize())
+	if err != nil {
+		log.Fatalf("Failed to decode map: %v", err)
+	}
+
+	// Draw the overlays
+	canvas := m.Canvas()
+	canvas.DrawGrid()
+	canvas.DrawMonuments()
+
+	if markers, err := client.GetMapMarkers(); err == nil {
+		canvas.DrawMarkers(markers)
+	} else {
+		log.Printf("Failed to get map markers: %v", err)
+	}
+
+	if team, err := client.GetTeamInfo(); err == nil {
+		canvas.DrawNotes(team)
+		canvas.DrawTeam(team)
+	} else {
+		log.Printf("Failed to get team info: %v", err)
+	}
+
+	// Save the rendered map
+	if err := canvas.SavePNG(outputFile); err != nil {
+		log.Fatalf("Failed to save map: %v", err)
+	}
+
+	fmt.Printf("Map saved to %s\n", outputFile)
+}
@@ -243,7 +243,7 @@ func (c *Client) processDataMessage(msg *fcmproto.DataMessageStanza) {
 	// Check if the message has crypto keys
 	hasCryptoKey := false
 	for _, appData := range msg.AppData {
-		if appData.Key == proto.String("crypto-key") {
+		if appData.GetKey() == "crypto-key" {
 			hasCryptoKey = true
 			break
 		}
@@ -258,7 +258,7 @@ func (c *Client) processDataMessage(msg *fcmproto.DataMessageStanza) {
 		// Emit raw data message
 		if c.onNotification != nil {
 			c.onNotification(Notification{
-				Message:      map[string]interface{}{"raw": true},
+				Message:      map[string]interface{}{"raw": true, "data": appDataMap(msg)},
 				PersistentID: *msg.PersistentId,
 				Object:       msg,
 			})
@@ -326,6 +326,15 @@ func (c *Client) processDataMessage(msg *fcmproto.DataMessageStanza) {
 	}
 }
 
+// appDataMap returns the app data of an unencrypted message as a map
+func appDataMap(msg *fcmproto.DataMessageStanza) map[string]interface{} {
+	data := make(map[string]interface{}, len(msg.AppData))
+	for _, appData := range msg.AppData {
+		data[appData.GetKey()] = appData.GetValue()
+	}
+	return data
+}
+
 // createLoginBuffer creates the login request buffer
 func (c *Client) createLoginBuffer() ([]byte, error) {
 	// Convert Android ID to hex
@@ -378,6 +387,7 @@ func (c *Client) createLoginBuffer() ([]byte, error) {
 func isIgnorableDecryptError(err error) bool {
 	errMsg := err.Error()
 	return strings.Contains(errMsg, "Unsupported state or unable to authenticate data") ||
+		strings.Contains(errMsg, "message authentication failed") ||
 		strings.Contains(errMsg, "crypto-key is missing") ||
 		strings.Contains(errMsg, "salt is missing")
 }
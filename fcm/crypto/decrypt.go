@@ -2,10 +2,12 @@ package crypto
 
 import (
 	"crypto/ecdh"
+	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -65,39 +67,25 @@ func DecryptMessage(message EncryptedMessage, keys Keys) (map[string]interface{}
 	}
 
 	// Decode the private key
-	privateKeyBytes, err := base64.StdEncoding.DecodeString(keys.PrivateKey)
+	privateKey, err := ParsePrivateKey(keys.PrivateKey)
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse the private key
-	// Note: In the original Node.js code, they use crypto.createECDH('prime256v1')
-	// In Go, we need to parse the private key from the bytes
-	parsedKey, err := x509.ParsePKCS8PrivateKey(privateKeyBytes)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert to ECDH private key
-	privateKey, ok := parsedKey.(*ecdh.PrivateKey)
-	if !ok {
-		return nil, errors.New("invalid private key type")
-	}
-
 	// Decode the auth secret
-	authSecret, err := base64.StdEncoding.DecodeString(keys.AuthSecret)
+	authSecret, err := DecodeBase64(keys.AuthSecret)
 	if err != nil {
 		return nil, err
 	}
 
 	// Decode the DH value
-	dhBytes, err := base64.StdEncoding.DecodeString(dhValue)
+	dhBytes, err := DecodeBase64(dhValue)
 	if err != nil {
 		return nil, err
 	}
 
 	// Decode the salt
-	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	saltBytes, err := DecodeBase64(salt)
 	if err != nil {
 		return nil, err
 	}
@@ -126,3 +114,38 @@ func DecryptMessage(message EncryptedMessage, keys Keys) (map[string]interface{}
 
 	return result, nil
 }
+
+// ParsePrivateKey decodes a base64 P-256 private key, either the raw 32 byte
+// scalar used by push-receiver or PKCS #8 DER
+func ParsePrivateKey(encoded string) (*ecdh.PrivateKey, error) {
+	data, err := DecodeBase64(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key encoding: %w", err)
+	}
+
+	if len(data) == 32 {
+		return ecdh.P256().NewPrivateKey(data)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	switch key := parsed.(type) {
+	case *ecdsa.PrivateKey:
+		return key.ECDH()
+	case *ecdh.PrivateKey:
+		return key, nil
+	default:
+		return nil, errors.New("invalid private key type")
+	}
+}
+
+// DecodeBase64 decodes standard or URL-safe base64, with or without padding,
+// as web push values come in either form
+func DecodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("-", "+", "_", "/").Replace(s)
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -3,6 +3,7 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ecdh"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
@@ -40,7 +41,7 @@ type ECEParams struct {
 	AuthSecret []byte
 	KeyID      string
 	DH         []byte
-	PrivateKey interface{} // This would be a crypto.PrivateKey in practice
+	PrivateKey interface{} // *ecdh.PrivateKey used with DH and for web push
 	RS         int
 	KeyMap     map[string][]byte
 }
@@ -108,9 +109,8 @@ func Encrypt(buffer []byte, params ECEParams) ([]byte, error) {
 	var result []byte
 	if header.Version == VersionAes128Gcm {
 		// Save the DH public key in the header unless keyid is set
-		if header.PrivateKey != nil && header.KeyID == "" {
-			// In a real implementation, we would get the public key from the private key
-			// header.KeyID = header.PrivateKey.PublicKey
+		if privateKey, ok := header.PrivateKey.(*ecdh.PrivateKey); ok && header.KeyID == "" {
+			header.KeyID = string(privateKey.PublicKey().Bytes())
 		}
 		headerBytes, err := writeHeader(header)
 		if err != nil {
@@ -282,9 +282,11 @@ func extractSecretAndContext(header ECEParams, mode string) (secretContext, erro
 			return result, fmt.Errorf("an explicit key must be %d bytes", KeyLength)
 		}
 	} else if header.DH != nil {
-		// This would extract the DH secret in a real implementation
-		// For now, we'll just return an error
-		return result, errors.New("DH extraction not implemented")
+		dh, err := extractDH(header, mode)
+		if err != nil {
+			return result, err
+		}
+		result = dh
 	} else if header.KeyID != "" {
 		result.Secret = header.KeyMap[header.KeyID]
 	}
@@ -322,19 +324,89 @@ func extractSecret(header ECEParams, mode string, keyLookupCallback func(string)
 		return key, nil
 	}
 
-	// This would compute the WebPush secret in a real implementation
-	// For now, we'll just return an error
-	return nil, errors.New("WebPush secret extraction not implemented")
+	return webpushSecret(header, mode)
+}
+
+// extractDH computes the shared secret of the aesgcm version from the local
+// private key and the remote public key in DH, and the context binding both
+// public keys
+func extractDH(header ECEParams, mode string) (secretContext, error) {
+	privateKey, ok := header.PrivateKey.(*ecdh.PrivateKey)
+	if !ok {
+		return secretContext{}, errors.New("DH requires an ECDH private key")
+	}
+
+	secret, err := computeSecret(privateKey, header.DH)
+	if err != nil {
+		return secretContext{}, err
+	}
+
+	local := privateKey.PublicKey().Bytes()
+	sender, receiver := header.DH, local
+	if mode == ModeEncrypt {
+		sender, receiver = local, header.DH
+	}
+
+	context := []byte("P-256\x00")
+	context = append(context, lengthPrefix(receiver)...)
+	context = append(context, lengthPrefix(sender)...)
+
+	return secretContext{Secret: secret, Context: context}, nil
+}
+
+// webpushSecret computes the input keying material of the aes128gcm version
+// for web push (RFC 8291). When decrypting, the key ID holds the sender's
+// public key.
+func webpushSecret(header ECEParams, mode string) ([]byte, error) {
+	privateKey, ok := header.PrivateKey.(*ecdh.PrivateKey)
+	if !ok {
+		return nil, errors.New("web push requires an ECDH private key")
+	}
+	if header.AuthSecret == nil {
+		return nil, errors.New("no authentication secret for web push")
+	}
+
+	local := privateKey.PublicKey().Bytes()
+	remote := []byte(header.KeyID)
+	sender, receiver := remote, local
+	if mode == ModeEncrypt {
+		remote = header.DH
+		sender, receiver = local, remote
+	}
+
+	secret, err := computeSecret(privateKey, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	info := append([]byte("WebPush: info\x00"), receiver...)
+	info = append(info, sender...)
+	return hkdf(header.AuthSecret, secret, info, Sha256Length), nil
+}
+
+// computeSecret computes the ECDH shared secret with a remote public key
+func computeSecret(privateKey *ecdh.PrivateKey, remote []byte) ([]byte, error) {
+	publicKey, err := privateKey.Curve().NewPublicKey(remote)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DH public key: %w", err)
+	}
+	return privateKey.ECDH(publicKey)
+}
+
+// lengthPrefix prefixes data with its length as two big endian bytes
+func lengthPrefix(data []byte) []byte {
+	result := make([]byte, 2, 2+len(data))
+	binary.BigEndian.PutUint16(result, uint16(len(data)))
+	return append(result, data...)
 }
 
 func generateNonce(base []byte, counter int) []byte {
 	nonce := make([]byte, len(base))
 	copy(nonce, base)
 
-	// The original implementation does some XOR operations on the last 6 bytes
-	// For simplicity, we'll just increment the last byte by the counter
-	if len(nonce) > 0 {
-		nonce[len(nonce)-1] ^= byte(counter)
+	// XOR the counter into the last 48 bits
+	for i := 0; i < 6 && i < len(nonce); i++ {
+		nonce[len(nonce)-1-i] ^= byte(uint64(counter) >> (8 * i))
 	}
 
 	return nonce
@@ -0,0 +1,36 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+// TestDecrypt_WebPush tests decrypting the example message of RFC 8291
+func TestDecrypt_WebPush(t *testing.T) {
+	decode := func(s string) []byte {
+		data, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("Invalid base64 %q: %v", s, err)
+		}
+		return data
+	}
+
+	privateKey, err := ecdh.P256().NewPrivateKey(decode("q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	if err != nil {
+		t.Fatalf("Invalid private key: %v", err)
+	}
+
+	message := decode("DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+	plaintext, err := Decrypt(message, ECEParams{
+		Version:    VersionAes128Gcm,
+		AuthSecret: decode("BTBZMqHH6r4Tts7J_aSIgg"),
+		PrivateKey: privateKey,
+	})
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if string(plaintext) != "When I grow up, I want to be a watermelon" {
+		t.Errorf("Unexpected plaintext %q", plaintext)
+	}
+}
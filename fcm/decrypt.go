@@ -42,12 +42,10 @@ func DecryptNotification(notification Notification, credentials Credentials) (ma
 		RawData: rawData,
 	}
 
-	// Extract keys from credentials
-	keys := crypto.Keys{
-		PrivateKey: credentials.FCM.Keys.Private,
-		AuthSecret: credentials.FCM.Keys.Auth,
-	}
-
 	// Decrypt the message
+	keys, err := credentials.FCM.Keys.decryption()
+	if err != nil {
+		return nil, err
+	}
 	return crypto.DecryptMessage(message, keys)
 }
@@ -20,6 +20,10 @@ const (
 	// URLs for GCM/FCM registration
 	registerURL = "https://android.clients.google.com/c2dm/register3"
 	checkinURL  = "https://android.clients.google.com/checkin"
+
+	// maxRegisterRetries is how many times a failed registration is retried
+	// by default
+	maxRegisterRetries = 5
 )
 
 // Credentials represents the GCM registration credentials
@@ -30,16 +34,55 @@ type Credentials struct {
 	AppID         string `json:"appId"`
 }
 
+// Client checks in and registers with GCM. The URLs can be pointed at a
+// local stand-in for testing.
+type Client struct {
+	CheckinURL  string
+	RegisterURL string
+	HTTPClient  *http.Client
+	// MaxRetries is how many times a registration is retried after a
+	// transport error, a server error or an "Error=" reply. Other failures,
+	// such as a rejected AidLogin, are returned straight away.
+	MaxRetries int
+	// RetryDelay is how long to wait before retrying a failed registration
+	RetryDelay time.Duration
+}
+
+// DefaultClient talks to the Google endpoints
+var DefaultClient = NewClient()
+
+// NewClient creates a client for the Google endpoints
+func NewClient() *Client {
+	return &Client{
+		CheckinURL:  checkinURL,
+		RegisterURL: registerURL,
+		HTTPClient:  http.DefaultClient,
+		MaxRetries:  maxRegisterRetries,
+		RetryDelay:  time.Second,
+	}
+}
+
 // Register registers with GCM and returns credentials
 func Register(androidID, securityToken, appID string) (Credentials, error) {
+	return DefaultClient.Register(androidID, securityToken, appID)
+}
+
+// CheckIn performs a check-in with GCM
+func CheckIn(androidID, securityToken string) (*fcmproto.AndroidCheckinResponse, error) {
+	return DefaultClient.CheckIn(androidID, securityToken)
+}
+
+// Register checks in and registers with GCM and returns credentials. An
+// empty Android ID and security token check in as a new device.
+func (c *Client) Register(androidID, securityToken, appID string) (Credentials, error) {
 	// First, check in with GCM
-	checkinResp, err := CheckIn(androidID, securityToken)
+	checkinResp, err := c.CheckIn(androidID, securityToken)
 	if err != nil {
 		return Credentials{}, fmt.Errorf("checkin failed: %w", err)
 	}
 
 	// Then register with the obtained credentials
-	credentials, err := doRegister(checkinResp, appID)
+	credentials, err := c.doRegister(checkinResp, appID)
 	if err != nil {
 		return Credentials{}, fmt.Errorf("registration failed: %w", err)
 	}
@@ -48,7 +91,7 @@ func Register(androidID, securityToken, appID string) (Credentials, error) {
 }
 
 // CheckIn performs a check-in with GCM
-func CheckIn(androidID, securityToken string) (*fcmproto.AndroidCheckinResponse, error) {
+func (c *Client) CheckIn(androidID, securityToken string) (*fcmproto.AndroidCheckinResponse, error) {
 	// Create the check-in request
 	request, err := getCheckinRequest(androidID, securityToken)
 	if err != nil {
@@ -62,7 +105,7 @@ func CheckIn(androidID, securityToken string) (*fcmproto.AndroidCheckinResponse,
 	}
 
 	// Send the request
-	resp, err := http.Post(checkinURL, "application/x-protobuf", bytes.NewReader(data))
+	resp, err := c.HTTPClient.Post(c.CheckinURL, "application/x-protobuf", bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("check-in request failed: %w", err)
 	}
@@ -73,6 +116,9 @@ func CheckIn(androidID, securityToken string) (*fcmproto.AndroidCheckinResponse,
 	if err != nil {
 		return nil, fmt.Errorf("failed to read check-in response: %w", err)
 	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("check-in failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
 
 	// Decode the response
 	response := &fcmproto.AndroidCheckinResponse{}
@@ -84,7 +130,7 @@ func CheckIn(androidID, securityToken string) (*fcmproto.AndroidCheckinResponse,
 }
 
 // doRegister performs the actual registration with GCM
-func doRegister(checkinResp *fcmproto.AndroidCheckinResponse, appID string) (Credentials, error) {
+func (c *Client) doRegister(checkinResp *fcmproto.AndroidCheckinResponse, appID string) (Credentials, error) {
 	androidID := fmt.Sprintf("%d", checkinResp.GetAndroidId())
 	securityToken := fmt.Sprintf("%d", checkinResp.GetSecurityToken())
 
@@ -93,22 +139,20 @@ func doRegister(checkinResp *fcmproto.AndroidCheckinResponse, appID string) (Cre
 	form.Add("app", "org.chromium.linux")
 	form.Add("X-subtype", appID)
 	form.Add("device", androidID)
-	form.Add("sender", base64.StdEncoding.EncodeToString(serverkey.Key))
+	form.Add("sender", base64.RawURLEncoding.EncodeToString(serverkey.Key))
 
 	// Send the registration request
-	response, err := postRegister(androidID, securityToken, form, 0)
+	response, err := c.postRegister(androidID, securityToken, form)
 	if err != nil {
 		return Credentials{}, err
 	}
 
-	// Parse the response
-	parts := strings.Split(response, "=")
-	if len(parts) < 2 {
+	// Parse the response, "token=<token>"
+	token, ok := strings.CutPrefix(strings.TrimSpace(response), "token=")
+	if !ok || token == "" {
 		return Credentials{}, errors.New("invalid registration response format")
 	}
 
-	token := parts[1]
-
 	return Credentials{
 		Token:         token,
 		AndroidID:     androidID,
@@ -117,12 +161,34 @@ func doRegister(checkinResp *fcmproto.AndroidCheckinResponse, appID string) (Cre
 	}, nil
 }
 
-// postRegister sends a registration request to GCM with retry logic
-func postRegister(androidID, securityToken string, form url.Values, retry int) (string, error) {
+// postRegister sends a registration request to GCM, retrying failures that
+// may be temporary
+func (c *Client) postRegister(androidID, securityToken string, form url.Values) (string, error) {
+	for retry := 0; ; retry++ {
+		response, retryable, err := c.tryRegister(androidID, securityToken, form)
+		if err == nil {
+			return response, nil
+		}
+		if !retryable {
+			return "", err
+		}
+
+		if retry >= c.MaxRetries {
+			return "", fmt.Errorf("GCM register has failed after %d retries: %w", retry, err)
+		}
+
+		// Wait and retry
+		time.Sleep(c.RetryDelay)
+	}
+}
+
+// tryRegister sends a registration request to GCM once and reports whether
+// a failure is worth retrying
+func (c *Client) tryRegister(androidID, securityToken string, form url.Values) (string, bool, error) {
 	// Create the request
-	req, err := http.NewRequest("POST", registerURL, strings.NewReader(form.Encode()))
+	req, err := http.NewRequest("POST", c.RegisterURL, strings.NewReader(form.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("failed to create registration request: %w", err)
+		return "", false, fmt.Errorf("failed to create registration request: %w", err)
 	}
 
 	// Set headers
@@ -130,33 +196,32 @@ func postRegister(androidID, securityToken string, form url.Values, retry int) (
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("registration request failed: %w", err)
+		return "", true, fmt.Errorf("registration request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read the response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read registration response: %w", err)
+		return "", true, fmt.Errorf("failed to read registration response: %w", err)
 	}
 
-	response := string(body)
+	response := strings.TrimSpace(string(body))
 
-	// Check for errors
-	if strings.Contains(response, "Error") {
-		if retry >= 5 {
-			return "", errors.New("GCM register has failed after 5 retries")
-		}
+	// Server errors may pass, client errors will not
+	if resp.StatusCode != http.StatusOK {
+		return "", resp.StatusCode >= 500, fmt.Errorf("registration failed with status %d: %s", resp.StatusCode, response)
+	}
 
-		// Wait and retry
-		time.Sleep(1 * time.Second)
-		return postRegister(androidID, securityToken, form, retry+1)
+	// Errors reported as "Error=<reason>", such as PHONE_REGISTRATION_ERROR,
+	// are usually temporary
+	if strings.Contains(response, "Error=") {
+		return "", true, fmt.Errorf("registration failed: %s", response)
 	}
 
-	return response, nil
+	return response, false, nil
 }
 
 // getCheckinRequest creates a check-in request protobuf
@@ -0,0 +1,59 @@
+package gcm
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fcmproto "github.com/chickenfresh/go-rustplus/fcm/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestClient_RegisterRetry tests that only failures that may be temporary
+// are retried
+func TestClient_RegisterRetry(t *testing.T) {
+	var replies []func(w http.ResponseWriter)
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/checkin", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := proto.Marshal(&fcmproto.AndroidCheckinResponse{
+			StatsOk:       proto.Bool(true),
+			AndroidId:     proto.Uint64(1234),
+			SecurityToken: proto.Uint64(5678),
+		})
+		w.Write(data)
+	})
+	mux.HandleFunc("/register3", func(w http.ResponseWriter, r *http.Request) {
+		replies[calls](w)
+		calls++
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient()
+	client.CheckinURL = server.URL + "/checkin"
+	client.RegisterURL = server.URL + "/register3"
+	client.RetryDelay = 0
+
+	// A rejected login is returned straight away
+	replies = []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) { http.Error(w, "Unauthorized", http.StatusUnauthorized) },
+	}
+	if _, err := client.Register("", "", "app"); err == nil || calls != 1 {
+		t.Errorf("Expected a single failed attempt, got %d attempts and %v", calls, err)
+	}
+
+	// Server errors and error replies are retried
+	calls = 0
+	replies = []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) { http.Error(w, "Unavailable", http.StatusServiceUnavailable) },
+		func(w http.ResponseWriter) { io.WriteString(w, "Error=PHONE_REGISTRATION_ERROR") },
+		func(w http.ResponseWriter) { io.WriteString(w, "token=gcm-token") },
+	}
+	credentials, err := client.Register("", "", "app")
+	if err != nil || calls != 3 || credentials.Token != "gcm-token" {
+		t.Errorf("Expected success on the third attempt, got %d attempts, %+v and %v", calls, credentials, err)
+	}
+}
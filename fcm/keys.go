@@ -0,0 +1,254 @@
+package fcm
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/chickenfresh/go-rustplus/fcm/crypto"
+)
+
+// authSecretSize is the size of a web push auth secret (RFC 8291)
+const authSecretSize = 16
+
+// ErrLegacyKeys is returned for credentials holding the RSA keys that older
+// versions of RegisterAndroid generated, which cannot decrypt web pushes
+var ErrLegacyKeys = errors.New("legacy RSA keys")
+
+// Keys are the web push keys messages are encrypted for: a P-256 key pair and
+// an auth secret. Each is standard base64; the private key is the raw 32 byte
+// scalar, as push-receiver stores it, and the public key the uncompressed
+// point. PKCS #8 private keys and URL-safe base64 are accepted as well.
+type Keys struct {
+	Private string `json:"private"`
+	Public  string `json:"public"`
+	Auth    string `json:"auth"`
+}
+
+// UnmarshalJSON reads keys saved by this package or by push-receiver, which
+// names the fields privateKey, publicKey and authSecret
+func (k *Keys) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		Private    string `json:"private"`
+		Public     string `json:"public"`
+		Auth       string `json:"auth"`
+		PrivateKey string `json:"privateKey"`
+		PublicKey  string `json:"publicKey"`
+		AuthSecret string `json:"authSecret"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*k = Keys{Private: fields.Private, Public: fields.Public, Auth: fields.Auth}
+	if k.Private == "" {
+		k.Private = fields.PrivateKey
+	}
+	if k.Public == "" {
+		k.Public = fields.PublicKey
+	}
+	if k.Auth == "" {
+		k.Auth = fields.AuthSecret
+	}
+	return nil
+}
+
+// GenerateKeys generates a new P-256 key pair and auth secret
+func GenerateKeys() (Keys, error) {
+	privateKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return Keys{}, fmt.Errorf("failed to generate ECDH key: %w", err)
+	}
+
+	authSecret := make([]byte, authSecretSize)
+	if _, err := rand.Read(authSecret); err != nil {
+		return Keys{}, fmt.Errorf("failed to generate auth secret: %w", err)
+	}
+
+	return NewKeys(privateKey, authSecret)
+}
+
+// NewKeys creates keys from a P-256 private key and auth secret
+func NewKeys(privateKey *ecdh.PrivateKey, authSecret []byte) (Keys, error) {
+	if privateKey.Curve() != ecdh.P256() {
+		return Keys{}, errors.New("private key is not P-256")
+	}
+	if len(authSecret) != authSecretSize {
+		return Keys{}, fmt.Errorf("auth secret must be %d bytes, got %d", authSecretSize, len(authSecret))
+	}
+
+	return Keys{
+		Private: base64.StdEncoding.EncodeToString(privateKey.Bytes()),
+		Public:  base64.StdEncoding.EncodeToString(privateKey.PublicKey().Bytes()),
+		Auth:    base64.StdEncoding.EncodeToString(authSecret),
+	}, nil
+}
+
+// ParseKeysPEM creates keys from a PEM encoded private key, either PKCS #8
+// ("PRIVATE KEY") or SEC 1 ("EC PRIVATE KEY"), and a base64 auth secret
+func ParseKeysPEM(data []byte, authSecret string) (Keys, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Keys{}, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return Keys{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return Keys{}, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	var privateKey *ecdh.PrivateKey
+	switch key := parsed.(type) {
+	case *ecdsa.PrivateKey:
+		if privateKey, err = key.ECDH(); err != nil {
+			return Keys{}, fmt.Errorf("invalid private key: %w", err)
+		}
+	case *ecdh.PrivateKey:
+		privateKey = key
+	default:
+		return Keys{}, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	auth, err := crypto.DecodeBase64(authSecret)
+	if err != nil {
+		return Keys{}, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	return NewKeys(privateKey, auth)
+}
+
+// PrivateKey decodes the private key
+func (k Keys) PrivateKey() (*ecdh.PrivateKey, error) {
+	if isRSAKey(k.Private) {
+		return nil, ErrLegacyKeys
+	}
+	return crypto.ParsePrivateKey(k.Private)
+}
+
+// AuthSecret decodes the auth secret
+func (k Keys) AuthSecret() ([]byte, error) {
+	auth, err := crypto.DecodeBase64(k.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+	if len(auth) != authSecretSize {
+		return nil, fmt.Errorf("auth secret must be %d bytes, got %d", authSecretSize, len(auth))
+	}
+	return auth, nil
+}
+
+// Validate checks that the keys are a matching P-256 pair with a valid auth
+// secret
+func (k Keys) Validate() error {
+	privateKey, err := k.PrivateKey()
+	if err != nil {
+		return err
+	}
+	if _, err := k.AuthSecret(); err != nil {
+		return err
+	}
+
+	public, err := crypto.DecodeBase64(k.Public)
+	if err != nil {
+		return fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	if string(public) != string(privateKey.PublicKey().Bytes()) {
+		return errors.New("public key does not match private key")
+	}
+	return nil
+}
+
+// MarshalPEM encodes the private key as PKCS #8 PEM
+func (k Keys) MarshalPEM() ([]byte, error) {
+	privateKey, err := k.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicKeyURL returns the public key in unpadded URL-safe base64, as a push
+// subscription's p256dh key
+func (k Keys) PublicKeyURL() (string, error) {
+	public, err := crypto.DecodeBase64(k.Public)
+	if err != nil {
+		return "", fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(public), nil
+}
+
+// AuthURL returns the auth secret in unpadded URL-safe base64, as a push
+// subscription's auth key
+func (k Keys) AuthURL() (string, error) {
+	auth, err := k.AuthSecret()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(auth), nil
+}
+
+// decryption returns the keys in the form crypto.DecryptMessage takes, after
+// checking the private key so legacy keys fail with ErrLegacyKeys
+func (k Keys) decryption() (crypto.Keys, error) {
+	if _, err := k.PrivateKey(); err != nil {
+		return crypto.Keys{}, err
+	}
+	return crypto.Keys{PrivateKey: k.Private, AuthSecret: k.Auth}, nil
+}
+
+// MigrateKeys replaces legacy RSA keys with new P-256 keys and reports
+// whether it did. Only RegisterAndroid ever generated RSA keys, and Android
+// registrations never send their keys to FCM, so the new keys need no new
+// subscription. Callers should save the credentials after a migration.
+func (c *Credentials) MigrateKeys() (bool, error) {
+	if !isRSAKey(c.FCM.Keys.Private) {
+		return false, nil
+	}
+
+	keys, err := GenerateKeys()
+	if err != nil {
+		return false, err
+	}
+	c.FCM.Keys = keys
+	return true, nil
+}
+
+// isRSAKey reports whether a base64 private key is an RSA key, in PKCS #1 as
+// older versions generated, or PKCS #8
+func isRSAKey(encoded string) bool {
+	data, err := crypto.DecodeBase64(encoded)
+	if err != nil || len(data) == 0 {
+		return false
+	}
+
+	if _, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return true
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return false
+	}
+	_, ok := parsed.(*rsa.PrivateKey)
+	return ok
+}
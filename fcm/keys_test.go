@@ -0,0 +1,91 @@
+package fcm
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestKeys_PEM tests that generated keys are valid and survive a PEM round trip
+func TestKeys_PEM(t *testing.T) {
+	keys, err := GenerateKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	if err := keys.Validate(); err != nil {
+		t.Fatalf("Generated keys are invalid: %v", err)
+	}
+
+	data, err := keys.MarshalPEM()
+	if err != nil {
+		t.Fatalf("Failed to export keys: %v", err)
+	}
+	auth, err := keys.AuthURL()
+	if err != nil {
+		t.Fatalf("Failed to encode auth secret: %v", err)
+	}
+	imported, err := ParseKeysPEM(data, auth)
+	if err != nil {
+		t.Fatalf("Failed to import keys: %v", err)
+	}
+	if imported != keys {
+		t.Errorf("Expected %+v, got %+v", keys, imported)
+	}
+}
+
+// TestCredentials_MigrateKeys tests that RSA keys from old config files are
+// replaced and push-receiver field names are read
+func TestCredentials_MigrateKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	legacy, _ := json.Marshal(map[string]interface{}{
+		"fcm": map[string]interface{}{
+			"token": "token",
+			"keys": map[string]string{
+				"private": base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PrivateKey(rsaKey)),
+				"public":  "public",
+				"secret":  "",
+				"auth":    "auth",
+			},
+		},
+	})
+
+	var credentials Credentials
+	if err := json.Unmarshal(legacy, &credentials); err != nil {
+		t.Fatalf("Failed to parse credentials: %v", err)
+	}
+	if _, err := credentials.FCM.Keys.PrivateKey(); !errors.Is(err, ErrLegacyKeys) {
+		t.Errorf("Expected ErrLegacyKeys, got %v", err)
+	}
+	if _, err := DecryptNotification(Notification{Data: map[string]interface{}{}}, credentials); !errors.Is(err, ErrLegacyKeys) {
+		t.Errorf("Expected decrypting to fail with ErrLegacyKeys, got %v", err)
+	}
+
+	migrated, err := credentials.MigrateKeys()
+	if err != nil || !migrated {
+		t.Fatalf("Expected keys to be migrated, got %v, %v", migrated, err)
+	}
+	if err := credentials.FCM.Keys.Validate(); err != nil {
+		t.Errorf("Migrated keys are invalid: %v", err)
+	}
+	if migrated, _ := credentials.MigrateKeys(); migrated {
+		t.Error("Expected valid keys to be kept")
+	}
+
+	// Keys saved by push-receiver
+	var keys Keys
+	data, _ := json.Marshal(map[string]string{
+		"privateKey": credentials.FCM.Keys.Private,
+		"publicKey":  credentials.FCM.Keys.Public,
+		"authSecret": credentials.FCM.Keys.Auth,
+	})
+	if err := json.Unmarshal(data, &keys); err != nil || keys != credentials.FCM.Keys {
+		t.Errorf("Expected %+v, got %+v, %v", credentials.FCM.Keys, keys, err)
+	}
+}
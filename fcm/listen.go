@@ -1,6 +1,7 @@
 package fcm
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
@@ -25,8 +26,16 @@ type Notification struct {
 
 // Listen starts listening for FCM notifications
 func Listen(config ListenConfig) (<-chan Notification, error) {
+	return ListenContext(context.Background(), config)
+}
+
+// ListenContext starts listening for FCM notifications until ctx is done,
+// then stops the client and closes the channel
+func ListenContext(ctx context.Context, config ListenConfig) (<-chan Notification, error) {
 	// Create notification channel
 	notificationChan := make(chan Notification)
+	var chanMutex sync.Mutex
+	chanClosed := false
 
 	// Create FCM client
 	fcmClient := client.NewClient(
@@ -69,8 +78,16 @@ func Listen(config ListenConfig) (<-chan Notification, error) {
 			PersistentId: n.PersistentID,
 		}
 
-		// Send to channel
-		notificationChan <- notification
+		// Send to channel unless listening has stopped
+		chanMutex.Lock()
+		defer chanMutex.Unlock()
+		if chanClosed {
+			return
+		}
+		select {
+		case notificationChan <- notification:
+		case <-ctx.Done():
+		}
 	})
 
 	// Handle connection errors
@@ -87,13 +104,15 @@ func Listen(config ListenConfig) (<-chan Notification, error) {
 	// Wait for connection to be established
 	wg.Wait()
 
-	// Start a goroutine to handle cleanup when the channel is closed
+	// Stop the client and close the channel once ctx is done
 	go func() {
-		// When this goroutine exits, stop the client
-		defer fcmClient.Stop()
+		<-ctx.Done()
+		fcmClient.Stop()
 
-		// Wait for the stop signal
-		<-notificationChan
+		chanMutex.Lock()
+		chanClosed = true
+		close(notificationChan)
+		chanMutex.Unlock()
 	}()
 
 	return notificationChan, nil
@@ -1,11 +1,6 @@
 package fcm
 
 import (
-	"crypto/ecdh"
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/chickenfresh/go-rustplus/fcm/android"
+	"github.com/chickenfresh/go-rustplus/fcm/gcm"
 	"github.com/google/uuid"
 )
 
@@ -32,12 +28,7 @@ type GCMCredentials struct {
 type FCMCredentials struct {
 	Token   string `json:"token"`
 	PushSet string `json:"pushSet"`
-	Keys    struct {
-		Private string `json:"private"`
-		Public  string `json:"public"`
-		Secret  string `json:"secret"`
-		Auth    string `json:"auth"`
-	} `json:"keys"`
+	Keys    Keys   `json:"keys"`
 }
 
 // Credentials represents the authentication credentials for FCM
@@ -46,160 +37,108 @@ type Credentials struct {
 	FCM FCMCredentials `json:"fcm"`
 }
 
-// Keys represents the cryptographic keys used for FCM
-type Keys struct {
-	Private string `json:"privateKey"`
-	Public  string `json:"publicKey"`
-	Auth    string `json:"authSecret"`
-}
-
 // FCMResponse represents the response from FCM registration
 type FCMResponse struct {
-	Token string `json:"token"`
+	Token   string `json:"token"`
+	PushSet string `json:"pushSet"`
 }
 
-// Register registers with FCM and returns credentials
-func Register(senderId string) (Credentials, error) {
-	var credentials Credentials
-
-	// Generate a unique Android ID
-	androidId, err := generateAndroidId()
-	if err != nil {
-		return credentials, fmt.Errorf("failed to generate Android ID: %w", err)
-	}
-
-	// Generate security token
-	securityToken, err := generateSecurityToken()
-	if err != nil {
-		return credentials, fmt.Errorf("failed to generate security token: %w", err)
-	}
-
-	// Register with GCM
-	err = registerGCM(senderId, androidId, securityToken, &credentials)
-	if err != nil {
-		return credentials, fmt.Errorf("GCM registration failed: %w", err)
-	}
-
-	// Generate keys for FCM
-	err = generateKeys(&credentials)
-	if err != nil {
-		return credentials, fmt.Errorf("failed to generate keys: %w", err)
-	}
-
-	// Register with FCM
-	err = registerFCM(senderId, &credentials)
-	if err != nil {
-		return credentials, fmt.Errorf("FCM registration failed: %w", err)
-	}
-
-	return credentials, nil
+// Registrar registers with GCM and FCM. The URLs can be pointed at a local
+// stand-in for testing.
+type Registrar struct {
+	// GCM checks in and registers the device
+	GCM *gcm.Client
+	// SubscribeURL is where the GCM token is subscribed to FCM
+	SubscribeURL string
+	// EndpointURL is the base of the push endpoint given to FCM
+	EndpointURL string
+	HTTPClient  *http.Client
 }
 
-// generateAndroidId generates a unique Android ID
-func generateAndroidId() (string, error) {
-	uuid, err := uuid.NewRandom()
-	if err != nil {
-		return "", err
+// NewRegistrar creates a registrar for the Google endpoints
+func NewRegistrar() *Registrar {
+	return &Registrar{
+		GCM:          gcm.NewClient(),
+		SubscribeURL: Subscribe,
+		EndpointURL:  Endpoint,
+		HTTPClient:   http.DefaultClient,
 	}
-
-	// Convert UUID to Android ID format (remove hyphens and use only first 16 chars)
-	androidId := strings.Replace(uuid.String(), "-", "", -1)
-	return androidId[:16], nil
 }
 
-// generateSecurityToken generates a security token
-func generateSecurityToken() (string, error) {
-	// In the original implementation, this is a long integer
-	// For simplicity, we'll use a random number
-	token := make([]byte, 8)
-	_, err := rand.Read(token)
-	if err != nil {
-		return "", err
-	}
-
-	// Convert to hex string
-	return fmt.Sprintf("%x", token), nil
+// Register registers with FCM and returns credentials
+func Register(senderId string) (Credentials, error) {
+	return NewRegistrar().Register(senderId)
 }
 
-// registerGCM registers with GCM service
-func registerGCM(senderId, androidId, securityToken string, credentials *Credentials) error {
-	// TODO: Implement actual GCM registration
-	// This is a placeholder implementation
-
-	credentials.GCM.AndroidId = androidId
-	credentials.GCM.SecurityToken = securityToken
-	credentials.GCM.AppId = fmt.Sprintf("wp:receiver.push.com#%s", uuid.New().String())
-	credentials.GCM.Token = fmt.Sprintf("APA91b%s", uuid.New().String())
-
-	return nil
-}
+// Register checks in and registers a new device with GCM, then subscribes
+// its token to FCM with freshly generated P-256 keys
+func (r *Registrar) Register(senderId string) (Credentials, error) {
+	var credentials Credentials
 
-// generateKeys generates the necessary keys for FCM
-func generateKeys(credentials *Credentials) error {
-	// Generate RSA key pair
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	// Register with GCM as a new web push receiver
+	appId := fmt.Sprintf("wp:receiver.push.com#%s", uuid.New().String())
+	subscription, err := r.GCM.Register("", "", appId)
 	if err != nil {
-		return err
+		return credentials, fmt.Errorf("GCM registration failed: %w", err)
 	}
 
-	// Encode private key
-	privateKeyBytes := x509.MarshalPKCS1PrivateKey(privateKey)
-	credentials.FCM.Keys.Private = base64.StdEncoding.EncodeToString(privateKeyBytes)
-
-	// Encode public key
-	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
-	if err != nil {
-		return err
+	credentials.GCM = GCMCredentials{
+		Token:         subscription.Token,
+		AndroidId:     subscription.AndroidID,
+		SecurityToken: subscription.SecurityToken,
+		AppId:         subscription.AppID,
 	}
-	credentials.FCM.Keys.Public = base64.StdEncoding.EncodeToString(publicKeyBytes)
 
-	// Generate auth secret
-	authSecret := make([]byte, 16)
-	_, err = rand.Read(authSecret)
+	// Subscribe the GCM token to FCM
+	keys, response, err := r.RegisterFCM(senderId, subscription.Token)
 	if err != nil {
-		return err
+		return credentials, fmt.Errorf("FCM registration failed: %w", err)
 	}
-	credentials.FCM.Keys.Auth = base64.StdEncoding.EncodeToString(authSecret)
-
-	return nil
-}
-
-// registerFCM registers with FCM service
-func registerFCM(senderId string, credentials *Credentials) error {
-	// TODO: Implement actual FCM registration
-	// This is a placeholder implementation
 
-	credentials.FCM.Token = fmt.Sprintf("fcm-%s", uuid.New().String())
-	credentials.FCM.PushSet = uuid.New().String()
+	credentials.FCM.Token = response.Token
+	credentials.FCM.PushSet = response.PushSet
+	credentials.FCM.Keys = keys
 
-	return nil
+	return credentials, nil
 }
 
 // RegisterFCM registers with FCM using the provided sender ID and token
 func RegisterFCM(senderID, token string) (Keys, FCMResponse, error) {
+	return NewRegistrar().RegisterFCM(senderID, token)
+}
+
+// RegisterFCM subscribes a GCM token to FCM with new keys
+func (r *Registrar) RegisterFCM(senderID, token string) (Keys, FCMResponse, error) {
 	// Create keys
-	keys, err := createKeys()
+	keys, err := GenerateKeys()
 	if err != nil {
 		return Keys{}, FCMResponse{}, fmt.Errorf("failed to create keys: %w", err)
 	}
+	publicKey, err := keys.PublicKeyURL()
+	if err != nil {
+		return Keys{}, FCMResponse{}, err
+	}
+	authSecret, err := keys.AuthURL()
+	if err != nil {
+		return Keys{}, FCMResponse{}, err
+	}
 
 	// Prepare form data
 	form := url.Values{}
 	form.Add("authorized_entity", senderID)
-	form.Add("endpoint", fmt.Sprintf("%s/%s", Endpoint, token))
-	form.Add("encryption_key", formatURLBase64(keys.Public))
-	form.Add("encryption_auth", formatURLBase64(keys.Auth))
+	form.Add("endpoint", fmt.Sprintf("%s/%s", r.EndpointURL, token))
+	form.Add("encryption_key", publicKey)
+	form.Add("encryption_auth", authSecret)
 
 	// Create request
-	req, err := http.NewRequest("POST", Subscribe, strings.NewReader(form.Encode()))
+	req, err := http.NewRequest("POST", r.SubscribeURL, strings.NewReader(form.Encode()))
 	if err != nil {
 		return Keys{}, FCMResponse{}, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := r.HTTPClient.Do(req)
 	if err != nil {
 		return Keys{}, FCMResponse{}, fmt.Errorf("request failed: %w", err)
 	}
@@ -210,50 +149,20 @@ func RegisterFCM(senderID, token string) (Keys, FCMResponse, error) {
 	if err != nil {
 		return Keys{}, FCMResponse{}, fmt.Errorf("failed to read response: %w", err)
 	}
+	if resp.StatusCode != http.StatusOK {
+		return Keys{}, FCMResponse{}, fmt.Errorf("subscribe failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
 
 	// Parse response
 	var fcmResponse FCMResponse
 	if err := json.Unmarshal(body, &fcmResponse); err != nil {
 		return Keys{}, FCMResponse{}, fmt.Errorf("failed to parse response: %w", err)
 	}
-
-	return keys, fcmResponse, nil
-}
-
-// createKeys generates the cryptographic keys needed for FCM
-func createKeys() (Keys, error) {
-	// Generate ECDH key pair
-	curve := ecdh.P256()
-	privateKey, err := curve.GenerateKey(rand.Reader)
-	if err != nil {
-		return Keys{}, fmt.Errorf("failed to generate ECDH key: %w", err)
-	}
-
-	// Generate auth secret
-	authSecret := make([]byte, 16)
-	if _, err := rand.Read(authSecret); err != nil {
-		return Keys{}, fmt.Errorf("failed to generate auth secret: %w", err)
-	}
-
-	// Encode keys
-	keys := Keys{
-		Private: base64.StdEncoding.EncodeToString(privateKey.Bytes()),
-		Public:  base64.StdEncoding.EncodeToString(privateKey.PublicKey().Bytes()),
-		Auth:    base64.StdEncoding.EncodeToString(authSecret),
+	if fcmResponse.Token == "" {
+		return Keys{}, FCMResponse{}, fmt.Errorf("subscribe response has no token")
 	}
 
-	return keys, nil
-}
-
-// formatURLBase64 formats a base64 string for use in URLs
-func formatURLBase64(input string) string {
-	return strings.TrimRight(
-		strings.ReplaceAll(
-			strings.ReplaceAll(input, "+", "-"),
-			"/", "_",
-		),
-		"=",
-	)
+	return keys, fcmResponse, nil
 }
 
 // RegisterAndroid registers with FCM using the Android method
@@ -281,8 +190,8 @@ func RegisterAndroid(apiKey, projectID, gcmSenderID, gmsAppID, androidPackageNam
 	credentials.GCM.SecurityToken = resp.GCM.SecurityToken
 	credentials.FCM.Token = resp.FCM.Token
 
-	// Generate keys for FCM
-	err = generateKeys(&credentials)
+	// Generate web push keys for FCM
+	credentials.FCM.Keys, err = GenerateKeys()
 	if err != nil {
 		return credentials, fmt.Errorf("failed to generate keys: %w", err)
 	}
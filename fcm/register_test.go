@@ -0,0 +1,115 @@
+package fcm
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/chickenfresh/go-rustplus/fcm/crypto"
+	fcmproto "github.com/chickenfresh/go-rustplus/fcm/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestRegistrar_Register tests registering against a local stand-in for the
+// Google endpoints and decrypting a message sent to the new credentials
+func TestRegistrar_Register(t *testing.T) {
+	var subscribed url.Values
+	mux := http.NewServeMux()
+	mux.HandleFunc("/checkin", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := &fcmproto.AndroidCheckinRequest{}
+		if err := proto.Unmarshal(body, request); err != nil || request.Id != nil {
+			t.Errorf("Expected a check-in as a new device, got %v, %v", request, err)
+		}
+
+		data, _ := proto.Marshal(&fcmproto.AndroidCheckinResponse{
+			StatsOk:       proto.Bool(true),
+			AndroidId:     proto.Uint64(1234),
+			SecurityToken: proto.Uint64(5678),
+		})
+		w.Write(data)
+	})
+	mux.HandleFunc("/register3", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "AidLogin 1234:5678" {
+			t.Errorf("Unexpected authorization %q", auth)
+		}
+		r.ParseForm()
+		if r.Form.Get("device") != "1234" || r.Form.Get("sender") == "" {
+			t.Errorf("Unexpected registration %v", r.Form)
+		}
+		io.WriteString(w, "token=gcm-token")
+	})
+	mux.HandleFunc("/subscribe", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		subscribed = r.Form
+		json.NewEncoder(w).Encode(FCMResponse{Token: "fcm-token", PushSet: "push-set"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	registrar := NewRegistrar()
+	registrar.GCM.CheckinURL = server.URL + "/checkin"
+	registrar.GCM.RegisterURL = server.URL + "/register3"
+	registrar.SubscribeURL = server.URL + "/subscribe"
+
+	credentials, err := registrar.Register("976529667804")
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	if credentials.GCM.AndroidId != "1234" || credentials.GCM.SecurityToken != "5678" || credentials.GCM.Token != "gcm-token" {
+		t.Errorf("Unexpected GCM credentials %+v", credentials.GCM)
+	}
+	if credentials.FCM.Token != "fcm-token" || credentials.FCM.PushSet != "push-set" {
+		t.Errorf("Unexpected FCM credentials %+v", credentials.FCM)
+	}
+	if subscribed.Get("authorized_entity") != "976529667804" || subscribed.Get("endpoint") != Endpoint+"/gcm-token" {
+		t.Errorf("Unexpected subscription %v", subscribed)
+	}
+
+	// Encrypt a message to the subscribed public key as a push service would
+	receiverKey, err := base64.RawURLEncoding.DecodeString(subscribed.Get("encryption_key"))
+	if err != nil {
+		t.Fatalf("Invalid encryption key: %v", err)
+	}
+	authSecret, err := base64.RawURLEncoding.DecodeString(subscribed.Get("encryption_auth"))
+	if err != nil {
+		t.Fatalf("Invalid encryption auth: %v", err)
+	}
+
+	senderKey, _ := ecdh.P256().GenerateKey(rand.Reader)
+	salt := make([]byte, 16)
+	rand.Read(salt)
+
+	rawData, err := crypto.Encrypt([]byte(`{"data":{"channelId":"pairing"}}`), crypto.ECEParams{
+		Version:    crypto.VersionAesGcm,
+		AuthSecret: authSecret,
+		DH:         receiverKey,
+		PrivateKey: senderKey,
+		Salt:       salt,
+		RS:         4096,
+	})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	decrypted, err := crypto.DecryptMessage(crypto.EncryptedMessage{
+		AppData: []crypto.AppDataItem{
+			{Key: "crypto-key", Value: "dh=" + base64.RawURLEncoding.EncodeToString(senderKey.PublicKey().Bytes())},
+			{Key: "encryption", Value: "salt=" + base64.RawURLEncoding.EncodeToString(salt)},
+		},
+		RawData: rawData,
+	}, crypto.Keys{PrivateKey: credentials.FCM.Keys.Private, AuthSecret: credentials.FCM.Keys.Auth})
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if data, _ := decrypted["data"].(map[string]interface{}); data["channelId"] != "pairing" {
+		t.Errorf("Unexpected message %v", decrypted)
+	}
+}
@@ -9,4 +9,4 @@ require (
 	google.golang.org/protobuf v1.36.6
 )
 
-require github.com/gorilla/websocket v1.5.3 // indirect
+require github.com/gorilla/websocket v1.5.3
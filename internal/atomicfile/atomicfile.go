@@ -0,0 +1,74 @@
+// Package atomicfile replaces files by writing a temporary file next to them
+// and renaming it into place, so a crash leaves either the old or the new
+// contents, never a partly written file.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File is a temporary file that replaces its target once committed
+type File struct {
+	*os.File
+	path      string
+	committed bool
+}
+
+// Create creates a temporary file next to path with the given permissions
+func Create(path string, perm os.FileMode) (*File, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to set permissions: %w", err)
+	}
+	return &File{File: tmp, path: path}, nil
+}
+
+// Commit syncs the file and renames it over its target. The file stays open
+// as the target, so it can still be written to. If committing fails, the
+// temporary file is removed and the target is left as it was.
+func (f *File) Commit() error {
+	if err := f.Sync(); err != nil {
+		f.Discard()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		f.Discard()
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	f.committed = true
+	return nil
+}
+
+// Discard closes the file and removes it unless it has been committed
+func (f *File) Discard() {
+	f.Close()
+	if !f.committed {
+		os.Remove(f.Name())
+	}
+}
+
+// WriteFile writes data to path, replacing any existing file atomically
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := Create(path, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Discard()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Commit(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return nil
+}
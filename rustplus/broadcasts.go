@@ -0,0 +1,191 @@
+package rustplus
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// handlerEntry is a registered handler that can be removed while a dispatch is in progress
+type handlerEntry[T any] struct {
+	fn      T
+	removed atomic.Bool
+}
+
+// handlerList is a list of handlers that is safe to modify from inside a handler.
+// The zero value is an empty list ready to use.
+type handlerList[T any] struct {
+	mutex   sync.Mutex
+	entries []*handlerEntry[T]
+}
+
+// add registers a handler and returns a function that removes it
+func (l *handlerList[T]) add(fn T) func() {
+	entry := &handlerEntry[T]{fn: fn}
+
+	l.mutex.Lock()
+	l.entries = append(l.entries, entry)
+	l.mutex.Unlock()
+
+	return func() {
+		if entry.removed.Swap(true) {
+			return
+		}
+
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		for i, e := range l.entries {
+			if e == entry {
+				l.entries = append(l.entries[:i:i], l.entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// each calls fn for every registered handler until fn returns true. Handlers
+// added during the dispatch are not called; handlers removed during the
+// dispatch are skipped.
+func (l *handlerList[T]) each(fn func(T) bool) {
+	l.mutex.Lock()
+	entries := append([]*handlerEntry[T](nil), l.entries...)
+	l.mutex.Unlock()
+
+	for _, entry := range entries {
+		if entry.removed.Load() {
+			continue
+		}
+		if fn(entry.fn) {
+			return
+		}
+	}
+}
+
+// dispatch calls every registered handler with v
+func dispatch[T any](l *handlerList[func(T)], v T) {
+	l.each(func(fn func(T)) bool {
+		fn(v)
+		return false
+	})
+}
+
+// queueBroadcast hands a broadcast to the dispatch goroutine without blocking
+// the reader
+func (c *Client) queueBroadcast(broadcast *proto.AppBroadcast) {
+	c.broadcastMutex.Lock()
+	c.broadcastQueue = append(c.broadcastQueue, broadcast)
+	c.broadcastMutex.Unlock()
+
+	select {
+	case c.broadcastReady <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// dispatchBroadcasts calls the typed handlers for queued broadcasts, in the
+// order they arrived, until the client is closed. Running them off the reader
+// goroutine lets handlers make requests, whose responses the reader delivers.
+func (c *Client) dispatchBroadcasts() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-c.broadcastReady:
+		}
+
+		c.broadcastMutex.Lock()
+		queue := c.broadcastQueue
+		c.broadcastQueue = nil
+		c.broadcastMutex.Unlock()
+
+		for _, broadcast := range queue {
+			c.handleBroadcast(broadcast)
+		}
+	}
+}
+
+// handleBroadcast dispatches broadcasts sent by the server to the typed
+// handlers and emits the matching client events
+func (c *Client) handleBroadcast(broadcast *proto.AppBroadcast) {
+	if broadcast.EntityChanged != nil {
+		dispatch(&c.entityChangedHandlers, broadcast.GetEntityChanged())
+	}
+
+	if broadcast.TeamMessage != nil {
+		dispatch(&c.teamMessageHandlers, broadcast.GetTeamMessage().GetMessage())
+	}
+
+	if broadcast.TeamChanged != nil {
+		dispatch(&c.teamChangedHandlers, broadcast.GetTeamChanged())
+	}
+
+	if broadcast.CameraRays != nil {
+		dispatch(&c.cameraRaysHandlers, broadcast.GetCameraRays())
+	}
+
+	if broadcast.ClanChanged != nil {
+		clanChanged := ClanChanged{ClanInfo: broadcast.ClanChanged.GetClanInfo()}
+		c.emitEvent(EventClanChanged, clanChanged, nil)
+		dispatch(&c.clanChangedHandlers, clanChanged)
+	}
+
+	if broadcast.ClanMessage != nil {
+		clanMessage := broadcast.GetClanMessage()
+		message := newClanMessage(clanMessage.GetClanId(), clanMessage.GetMessage())
+		c.emitEvent(EventClanMessage, message, nil)
+		dispatch(&c.clanMessageHandlers, message)
+	}
+}
+
+// OnEntityChanged registers a handler for changes to the given entity and
+// returns a function that removes it. While the handler is registered, the
+// client re-requests the entity after reconnecting so broadcasts resume.
+func (c *Client) OnEntityChanged(entityID uint32, handler func(*proto.AppEntityChanged)) func() {
+	c.addEntityInterest(entityID)
+
+	remove := c.entityChangedHandlers.add(func(changed *proto.AppEntityChanged) {
+		if changed.GetEntityId() == entityID {
+			handler(changed)
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			remove()
+			c.removeEntityInterest(entityID)
+		})
+	}
+}
+
+// OnTeamMessage registers a handler for new team chat messages and returns a
+// function that removes it
+func (c *Client) OnTeamMessage(handler func(*proto.AppTeamMessage)) func() {
+	return c.teamMessageHandlers.add(handler)
+}
+
+// OnTeamChanged registers a handler for team changes and returns a function
+// that removes it
+func (c *Client) OnTeamChanged(handler func(*proto.AppTeamChanged)) func() {
+	return c.teamChangedHandlers.add(handler)
+}
+
+// OnCameraRays registers a handler for camera rays and returns a function
+// that removes it
+func (c *Client) OnCameraRays(handler func(*proto.AppCameraRays)) func() {
+	return c.cameraRaysHandlers.add(handler)
+}
+
+// OnClanChanged registers a handler for clan changes and returns a function
+// that removes it
+func (c *Client) OnClanChanged(handler func(ClanChanged)) func() {
+	return c.clanChangedHandlers.add(handler)
+}
+
+// OnClanMessage registers a handler for new clan chat messages and returns a
+// function that removes it
+func (c *Client) OnClanMessage(handler func(ClanMessage)) func() {
+	return c.clanMessageHandlers.add(handler)
+}
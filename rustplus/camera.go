@@ -15,16 +15,17 @@ import (
 
 // Camera represents a CCTV camera in Rust
 type Camera struct {
-	client         *Client
-	identifier     string
-	isSubscribed   bool
-	subscribeInfo  *proto.AppCameraInfo
-	cameraRays     []*proto.AppCameraRays
-	subscribeTimer *time.Timer
-	mutex          sync.RWMutex
-	ctx            context.Context
-	cancel         context.CancelFunc
-	eventChan      chan CameraEvent
+	client            *Client
+	identifier        string
+	isSubscribed      bool
+	subscribeInfo     *proto.AppCameraInfo
+	cameraRays        []*proto.AppCameraRays
+	subscribeTimer    *time.Timer
+	removeRaysHandler func()
+	mutex             sync.RWMutex
+	ctx               context.Context
+	cancel            context.CancelFunc
+	eventChan         chan CameraEvent
 }
 
 // CameraEventType represents the type of camera event
@@ -100,41 +101,39 @@ func (c *Camera) Subscribe() error {
 	}
 
 	// Send the request
-	response, err := c.client.SendRequestAsync(request, 10*time.Second)
+	response, err := c.client.request(c.ctx, request)
 	if err != nil {
 		c.emitEvent(CameraEventError, nil, fmt.Errorf("failed to subscribe to camera: %w", err))
 		return err
 	}
 
 	// Check if we got a valid response
-	if response.Response == nil || response.Response.CameraSubscribeInfo == nil {
-		err := errors.New("invalid camera subscribe response")
+	if response.CameraSubscribeInfo == nil {
+		err := fmt.Errorf("invalid camera subscribe response: %w", ErrInvalidResponse)
 		c.emitEvent(CameraEventError, nil, err)
 		return err
 	}
 
 	// Store the camera info
-	c.subscribeInfo = response.Response.CameraSubscribeInfo
+	c.subscribeInfo = response.CameraSubscribeInfo
 
 	// Set up a handler for camera rays
-	c.client.AddMessageHandler(func(msg *proto.AppMessage) bool {
-		if msg.Broadcast != nil && msg.Broadcast.CameraRays != nil {
-			c.handleCameraRays(msg.Broadcast.CameraRays)
-			return true
-		}
-		return false
-	})
+	c.removeRaysHandler = c.client.OnCameraRays(c.handleCameraRays)
 
 	// Start the subscription timer
 	c.startSubscriptionTimer()
 
+	// Restore the subscription if the client reconnects
+	c.client.addCamera(c)
+
 	c.isSubscribed = true
 	c.emitEvent(CameraEventSubscribed, c.subscribeInfo, nil)
 
 	return nil
 }
 
-// Unsubscribe unsubscribes from the camera
+// Unsubscribe unsubscribes from the camera. The camera is unsubscribed
+// locally even if the request fails, whose error is returned.
 func (c *Camera) Unsubscribe() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -151,19 +150,31 @@ func (c *Camera) Unsubscribe() error {
 		c.subscribeTimer = nil
 	}
 
+	// Stop restoring the subscription after reconnecting
+	c.client.removeCamera(c)
+
+	// Stop handling camera rays
+	if c.removeRaysHandler != nil {
+		c.removeRaysHandler()
+		c.removeRaysHandler = nil
+	}
+
+	// The camera is torn down even if the request fails, so it can be
+	// subscribed to again
+	c.isSubscribed = false
+
 	// Create the unsubscribe request
 	request := &proto.AppRequest{
 		CameraUnsubscribe: &proto.AppEmpty{},
 	}
 
 	// Send the request
-	_, err := c.client.SendRequestAsync(request, 10*time.Second)
+	_, err := c.client.request(c.ctx, request)
 	if err != nil {
 		c.emitEvent(CameraEventError, nil, fmt.Errorf("failed to unsubscribe from camera: %w", err))
 		return err
 	}
 
-	c.isSubscribed = false
 	c.emitEvent(CameraEventUnsubscribed, nil, nil)
 
 	return nil
@@ -190,7 +201,7 @@ func (c *Camera) Move(buttons CameraButtons, x, y float32) error {
 	}
 
 	// Send the request
-	_, err := c.client.SendRequestAsync(request, 10*time.Second)
+	_, err := c.client.request(c.ctx, request)
 	if err != nil {
 		return fmt.Errorf("failed to send camera input: %w", err)
 	}
@@ -281,24 +292,50 @@ func (c *Camera) startSubscriptionTimer() {
 
 	// Create a new timer that resubscribes every 30 seconds
 	c.subscribeTimer = time.AfterFunc(30*time.Second, func() {
-		// Resubscribe
-		request := &proto.AppRequest{
-			CameraSubscribe: &proto.AppCameraSubscribe{
-				CameraId: &c.identifier,
-			},
+		c.mutex.RLock()
+		subscribed := c.isSubscribed
+		c.mutex.RUnlock()
+		if !subscribed {
+			return
 		}
 
-		_, err := c.client.SendRequestAsync(request, 10*time.Second)
-		if err != nil {
+		// Resubscribe, keeping the timer running so the subscription is
+		// renewed once the client has reconnected
+		if err := c.resubscribe(); err != nil {
 			c.emitEvent(CameraEventError, nil, fmt.Errorf("failed to resubscribe to camera: %w", err))
-			return
 		}
 
 		// Restart the timer
-		c.startSubscriptionTimer()
+		c.mutex.Lock()
+		if c.isSubscribed {
+			c.startSubscriptionTimer()
+		}
+		c.mutex.Unlock()
 	})
 }
 
+// resubscribe renews the camera subscription on the server
+func (c *Camera) resubscribe() error {
+	request := &proto.AppRequest{
+		CameraSubscribe: &proto.AppCameraSubscribe{
+			CameraId: &c.identifier,
+		},
+	}
+
+	response, err := c.client.request(c.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if response.CameraSubscribeInfo != nil {
+		c.mutex.Lock()
+		c.subscribeInfo = response.CameraSubscribeInfo
+		c.mutex.Unlock()
+	}
+
+	return nil
+}
+
 // handleCameraRays processes camera rays data
 func (c *Camera) handleCameraRays(rays *proto.AppCameraRays) {
 	c.mutex.Lock()
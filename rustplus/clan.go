@@ -0,0 +1,139 @@
+package rustplus
+
+import (
+	"context"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// ClanChanged is emitted with EventClanChanged when the player's clan changes
+type ClanChanged struct {
+	// ClanInfo is nil when the player is no longer in a clan
+	ClanInfo *proto.ClanInfo
+}
+
+// ClanMessage is emitted with EventClanMessage when a message is posted in clan chat
+type ClanMessage struct {
+	ClanID  int64
+	SteamID uint64
+	Name    string
+	Message string
+	Time    time.Time
+}
+
+// newClanMessage converts a protocol buffer clan message
+func newClanMessage(clanID int64, m *proto.AppClanMessage) ClanMessage {
+	return ClanMessage{
+		ClanID:  clanID,
+		SteamID: m.GetSteamId(),
+		Name:    m.GetName(),
+		Message: m.GetMessage(),
+		Time:    time.Unix(m.GetTime(), 0),
+	}
+}
+
+// ClanRole returns the role with the given ID, or nil if the clan has no such role
+func ClanRole(info *proto.ClanInfo, roleID int32) *proto.ClanInfo_Role {
+	for _, role := range info.GetRoles() {
+		if role.GetRoleId() == roleID {
+			return role
+		}
+	}
+	return nil
+}
+
+// ClanMember returns the member with the given Steam ID, or nil if they are not in the clan
+func ClanMember(info *proto.ClanInfo, steamID uint64) *proto.ClanInfo_Member {
+	for _, member := range info.GetMembers() {
+		if member.GetSteamId() == steamID {
+			return member
+		}
+	}
+	return nil
+}
+
+// GetClanInfo gets information about the player's clan, including its roles, members and invites
+func (c *Client) GetClanInfo() (*proto.ClanInfo, error) {
+	return c.GetClanInfoContext(context.Background())
+}
+
+// GetClanInfoContext gets information about the player's clan using the provided context
+func (c *Client) GetClanInfoContext(ctx context.Context) (*proto.ClanInfo, error) {
+	request := &proto.AppRequest{
+		GetClanInfo: &proto.AppEmpty{},
+	}
+
+	response, err := c.request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.ClanInfo == nil {
+		return nil, ErrInvalidResponse
+	}
+
+	if response.ClanInfo.ClanInfo == nil {
+		return nil, ErrNoClan
+	}
+
+	return response.GetClanInfo().GetClanInfo(), nil
+}
+
+// GetClanChat gets the clan chat
+func (c *Client) GetClanChat() ([]*proto.AppClanMessage, error) {
+	return c.GetClanChatContext(context.Background())
+}
+
+// GetClanChatContext gets the clan chat using the provided context
+func (c *Client) GetClanChatContext(ctx context.Context) ([]*proto.AppClanMessage, error) {
+	request := &proto.AppRequest{
+		GetClanChat: &proto.AppEmpty{},
+	}
+
+	response, err := c.request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.ClanChat == nil {
+		return nil, ErrInvalidResponse
+	}
+
+	return response.GetClanChat().GetMessages(), nil
+}
+
+// SendClanMessage sends a message to the clan chat
+func (c *Client) SendClanMessage(message string) error {
+	return c.SendClanMessageContext(context.Background(), message)
+}
+
+// SendClanMessageContext sends a message to the clan chat using the provided context
+func (c *Client) SendClanMessageContext(ctx context.Context, message string) error {
+	request := &proto.AppRequest{
+		SendClanMessage: &proto.AppSendMessage{
+			Message: protobuf.String(message),
+		},
+	}
+
+	_, err := c.request(ctx, request)
+	return err
+}
+
+// SetClanMotd sets the clan's message of the day
+func (c *Client) SetClanMotd(motd string) error {
+	return c.SetClanMotdContext(context.Background(), motd)
+}
+
+// SetClanMotdContext sets the clan's message of the day using the provided context
+func (c *Client) SetClanMotdContext(ctx context.Context, motd string) error {
+	request := &proto.AppRequest{
+		SetClanMotd: &proto.AppSendMessage{
+			Message: protobuf.String(motd),
+		},
+	}
+
+	_, err := c.request(ctx, request)
+	return err
+}
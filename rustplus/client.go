@@ -2,7 +2,9 @@ package rustplus
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"sync"
 	"sync/atomic"
@@ -13,7 +15,9 @@ import (
 	protobuf "google.golang.org/protobuf/proto"
 )
 
-// Client represents a Rust+ client
+// Client represents a Rust+ client. Handlers registered with the On* methods
+// run one at a time on a dispatch goroutine of their own, not the connection
+// reader, so they may make requests.
 type Client struct {
 	// Configuration
 	server            string
@@ -21,43 +25,85 @@ type Client struct {
 	playerID          uint64
 	playerToken       int
 	useFacepunchProxy bool
+	requestTimeout    time.Duration
+	rateLimiter       *RateLimiter
 
 	// WebSocket connection
-	conn              *websocket.Conn
-	connMutex         sync.RWMutex
-	isConnected       bool
-	reconnectAttempts int
+	conn            *websocket.Conn
+	connMutex       sync.RWMutex
+	writeMutex      sync.Mutex
+	isConnected     bool
+	reconnecting    bool
+	reconnectPolicy ReconnectPolicy
 
 	// Message handling
 	seq              uint32
-	seqCallbacks     map[uint32]func(*proto.AppMessage) bool
+	seqCallbacks     map[uint32]chan requestResult
 	seqCallbackMutex sync.Mutex
-	messageHandlers  []func(*proto.AppMessage) bool
-	handlerMutex     sync.RWMutex
+	messageHandlers  handlerList[func(*proto.AppMessage) bool]
+
+	// Typed broadcast handlers
+	entityChangedHandlers handlerList[func(*proto.AppEntityChanged)]
+	teamMessageHandlers   handlerList[func(*proto.AppTeamMessage)]
+	teamChangedHandlers   handlerList[func(*proto.AppTeamChanged)]
+	cameraRaysHandlers    handlerList[func(*proto.AppCameraRays)]
+	clanChangedHandlers   handlerList[func(ClanChanged)]
+	clanMessageHandlers   handlerList[func(ClanMessage)]
+
+	// Broadcasts waiting for the dispatch goroutine
+	broadcastQueue []*proto.AppBroadcast
+	broadcastMutex sync.Mutex
+	broadcastReady chan struct{}
+
+	// State re-established after reconnecting
+	cameras        map[*Camera]struct{}
+	entityInterest map[uint32]int
+	resyncMutex    sync.Mutex
 
 	// Context for cancellation
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	// Event handling
-	eventChan chan Event
+	eventChan    chan Event
+	eventMutex   sync.RWMutex
+	eventsClosed bool
+}
+
+// requestResult is delivered to a pending request when it completes
+type requestResult struct {
+	msg *proto.AppMessage
+	err error
 }
 
 // NewClient creates a new Rust+ client
-func NewClient(server string, port int, playerID uint64, playerToken int, useFacepunchProxy bool) *Client {
+func NewClient(server string, port int, playerID uint64, playerToken int, useFacepunchProxy bool, opts ...ClientOption) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Client{
+	c := &Client{
 		server:            server,
 		port:              port,
 		playerID:          playerID,
 		playerToken:       playerToken,
 		useFacepunchProxy: useFacepunchProxy,
-		seqCallbacks:      make(map[uint32]func(*proto.AppMessage) bool),
+		requestTimeout:    DefaultRequestTimeout,
+		reconnectPolicy:   DefaultReconnectPolicy,
+		seqCallbacks:      make(map[uint32]chan requestResult),
+		cameras:           make(map[*Camera]struct{}),
+		entityInterest:    make(map[uint32]int),
 		ctx:               ctx,
 		cancel:            cancel,
 		eventChan:         make(chan Event, 100),
+		broadcastReady:    make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	go c.dispatchBroadcasts()
+
+	return c
 }
 
 // Events returns a channel of client events
@@ -67,6 +113,14 @@ func (c *Client) Events() <-chan Event {
 
 // emitEvent emits an event to the event channel
 func (c *Client) emitEvent(eventType EventType, data interface{}, err error) {
+	c.eventMutex.RLock()
+	defer c.eventMutex.RUnlock()
+
+	// The channel is closed once the client is closed
+	if c.eventsClosed {
+		return
+	}
+
 	select {
 	case c.eventChan <- Event{Type: eventType, Data: data, Error: err}:
 		// Event sent successfully
@@ -78,6 +132,12 @@ func (c *Client) emitEvent(eventType EventType, data interface{}, err error) {
 
 // Connect connects to the Rust+ server
 func (c *Client) Connect() error {
+	return c.ConnectContext(context.Background())
+}
+
+// ConnectContext connects to the Rust+ server, giving up on the dial when ctx
+// is done
+func (c *Client) ConnectContext(ctx context.Context) error {
 	c.connMutex.Lock()
 	defer c.connMutex.Unlock()
 
@@ -85,6 +145,10 @@ func (c *Client) Connect() error {
 		return nil
 	}
 
+	if c.ctx.Err() != nil {
+		return ErrClientClosed
+	}
+
 	c.emitEvent(EventConnecting, nil, nil)
 
 	// Determine the WebSocket URL
@@ -109,23 +173,65 @@ func (c *Client) Connect() error {
 	u.RawQuery = q.Encode()
 
 	// Connect to the WebSocket
-	dialer := websocket.DefaultDialer
-	conn, _, err := dialer.Dial(u.String(), nil)
+	conn, err := dial(ctx, u.String())
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 
 	c.conn = conn
 	c.isConnected = true
-	c.reconnectAttempts = 0
 
 	// Start the message reader
-	go c.readMessages()
+	go c.readMessages(conn)
 
 	c.emitEvent(EventConnected, nil, nil)
 	return nil
 }
 
+// dial opens a WebSocket connection. The dialer only gives up on a done ctx
+// while connecting, so the connection is also interrupted if ctx is done
+// during the handshake.
+func dial(ctx context.Context, url string) (*websocket.Conn, error) {
+	var mutex sync.Mutex
+	var netConn net.Conn
+
+	dialer := *websocket.DefaultDialer
+	dialer.NetDialContext = func(dialCtx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(dialCtx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		netConn = conn
+		if ctx.Err() != nil {
+			conn.SetDeadline(time.Now())
+		}
+		return conn, nil
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if netConn != nil {
+			netConn.SetDeadline(time.Now())
+		}
+	})
+
+	conn, _, err := dialer.DialContext(ctx, url, nil)
+	if !stop() && ctx.Err() != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, ctx.Err()
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return conn, err
+}
+
 // Disconnect disconnects from the Rust+ server
 func (c *Client) Disconnect() error {
 	c.connMutex.Lock()
@@ -140,6 +246,9 @@ func (c *Client) Disconnect() error {
 	c.isConnected = false
 	c.emitEvent(EventDisconnected, nil, nil)
 
+	// Requests still waiting for a response will never receive one
+	c.failPendingRequests(ErrDisconnected)
+
 	return err
 }
 
@@ -147,21 +256,28 @@ func (c *Client) Disconnect() error {
 func (c *Client) Close() error {
 	err := c.Disconnect()
 	c.cancel()
-	close(c.eventChan)
+
+	c.eventMutex.Lock()
+	if !c.eventsClosed {
+		c.eventsClosed = true
+		close(c.eventChan)
+	}
+	c.eventMutex.Unlock()
+
 	return err
 }
 
 // readMessages reads messages from the WebSocket connection
-func (c *Client) readMessages() {
+func (c *Client) readMessages(conn *websocket.Conn) {
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
 		default:
 			// Read the next message
-			_, message, err := c.conn.ReadMessage()
+			_, message, err := conn.ReadMessage()
 			if err != nil {
-				c.handleDisconnect(err)
+				c.handleDisconnect(conn, err)
 				return
 			}
 
@@ -181,25 +297,24 @@ func (c *Client) readMessages() {
 	}
 }
 
-// handleDisconnect handles a disconnection
-func (c *Client) handleDisconnect(err error) {
+// handleDisconnect handles the connection being lost
+func (c *Client) handleDisconnect(conn *websocket.Conn, err error) {
 	c.connMutex.Lock()
-	wasConnected := c.isConnected
+	// Ignore errors from a connection that has already been replaced or closed
+	if c.conn != conn || !c.isConnected {
+		c.connMutex.Unlock()
+		return
+	}
 	c.isConnected = false
 	c.connMutex.Unlock()
 
-	if wasConnected {
-		c.emitEvent(EventDisconnected, nil, err)
+	c.emitEvent(EventDisconnected, nil, err)
 
-		// Try to reconnect
-		c.reconnectAttempts++
-		if c.reconnectAttempts < 5 {
-			time.Sleep(time.Duration(c.reconnectAttempts) * time.Second)
-			if err := c.Connect(); err != nil {
-				c.emitEvent(EventError, nil, fmt.Errorf("failed to reconnect: %w", err))
-			}
-		}
-	}
+	// Requests still waiting for a response will never receive one
+	c.failPendingRequests(ErrDisconnected)
+
+	// Reconnect without blocking the reader goroutine
+	go c.reconnect()
 }
 
 // handleMessage handles an incoming message
@@ -209,37 +324,34 @@ func (c *Client) handleMessage(msg *proto.AppMessage) {
 		seq := msg.Response.GetSeq()
 
 		c.seqCallbackMutex.Lock()
-		callback, ok := c.seqCallbacks[seq]
+		resultChan, ok := c.seqCallbacks[seq]
+		delete(c.seqCallbacks, seq)
 		c.seqCallbackMutex.Unlock()
 
 		if ok {
-			if callback(msg) {
-				c.seqCallbackMutex.Lock()
-				delete(c.seqCallbacks, seq)
-				c.seqCallbackMutex.Unlock()
-			}
+			resultChan <- requestResult{msg: msg}
 		}
 	}
 
-	// Run message handlers
-	c.handlerMutex.RLock()
-	for _, handler := range c.messageHandlers {
-		if handler(msg) {
-			break
-		}
+	// Typed handlers run on the dispatch goroutine so they can make requests
+	if msg.Broadcast != nil {
+		c.queueBroadcast(msg.Broadcast)
 	}
-	c.handlerMutex.RUnlock()
+
+	// Run message handlers
+	c.messageHandlers.each(func(handler func(*proto.AppMessage) bool) bool {
+		return handler(msg)
+	})
 }
 
-// AddMessageHandler adds a message handler
-func (c *Client) AddMessageHandler(handler func(*proto.AppMessage) bool) {
-	c.handlerMutex.Lock()
-	c.messageHandlers = append(c.messageHandlers, handler)
-	c.handlerMutex.Unlock()
+// AddMessageHandler adds a message handler and returns a function that removes it.
+// Handlers run in order until one returns true.
+func (c *Client) AddMessageHandler(handler func(*proto.AppMessage) bool) func() {
+	return c.messageHandlers.add(handler)
 }
 
-// SendRequest sends a request to the server
-func (c *Client) SendRequest(req *proto.AppRequest) error {
+// prepareRequest assigns a sequence number and the player credentials to a request
+func (c *Client) prepareRequest(req *proto.AppRequest) uint32 {
 	// Increment the sequence number
 	seq := atomic.AddUint32(&c.seq, 1)
 	req.Seq = Uint32(seq)
@@ -248,6 +360,11 @@ func (c *Client) SendRequest(req *proto.AppRequest) error {
 	req.PlayerId = Uint64(c.playerID)
 	req.PlayerToken = Int32(int32(c.playerToken))
 
+	return seq
+}
+
+// writeRequest marshals a prepared request and writes it to the connection
+func (c *Client) writeRequest(req *proto.AppRequest) error {
 	// Marshal the message
 	data, err := protobuf.Marshal(req)
 	if err != nil {
@@ -262,7 +379,11 @@ func (c *Client) SendRequest(req *proto.AppRequest) error {
 		return fmt.Errorf("not connected")
 	}
 
-	if err := c.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+	// The WebSocket connection supports only one concurrent writer
+	c.writeMutex.Lock()
+	err = c.conn.WriteMessage(websocket.BinaryMessage, data)
+	c.writeMutex.Unlock()
+	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 
@@ -270,193 +391,265 @@ func (c *Client) SendRequest(req *proto.AppRequest) error {
 	return nil
 }
 
+// SendRequest sends a request to the server without waiting for a response
+func (c *Client) SendRequest(req *proto.AppRequest) error {
+	if err := c.waitRateLimit(context.Background(), req); err != nil {
+		return err
+	}
+
+	c.prepareRequest(req)
+	return c.writeRequest(req)
+}
+
+// waitRateLimit waits for the rate limiter, if any, to allow the request
+func (c *Client) waitRateLimit(ctx context.Context, req *proto.AppRequest) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+
+	if err := c.rateLimiter.Wait(ctx, RequestCost(req), RequestPriority(ctx, req)); err != nil {
+		return fmt.Errorf("request cancelled while rate limited: %w", err)
+	}
+
+	return nil
+}
+
 // SendRequestAsync sends a request to the server and waits for a response
 func (c *Client) SendRequestAsync(req *proto.AppRequest, timeout time.Duration) (*proto.AppMessage, error) {
-	// Create a channel for the response
-	responseChan := make(chan *proto.AppMessage, 1)
-	errChan := make(chan error, 1)
-
-	// Increment the sequence number
-	seq := atomic.AddUint32(&c.seq, 1)
-	req.Seq = Uint32(seq)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// Add player ID and token to the request
-	req.PlayerId = Uint64(c.playerID)
-	req.PlayerToken = Int32(int32(c.playerToken))
+	return c.SendRequestContext(ctx, req)
+}
 
-	// Register the callback
-	c.seqCallbackMutex.Lock()
-	c.seqCallbacks[seq] = func(msg *proto.AppMessage) bool {
-		select {
-		case responseChan <- msg:
-		default:
-		}
-		return true
+// SendRequestContext sends a request to the server and waits for a response
+// until ctx is done. If ctx has no deadline, the client's default request
+// timeout is applied.
+func (c *Client) SendRequestContext(ctx context.Context, req *proto.AppRequest) (*proto.AppMessage, error) {
+	if _, ok := ctx.Deadline(); !ok && c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
 	}
-	c.seqCallbackMutex.Unlock()
 
-	// Send the request
-	go func() {
-		// Marshal the message
-		data, err := protobuf.Marshal(req)
-		if err != nil {
-			errChan <- fmt.Errorf("failed to marshal request: %w", err)
-			return
-		}
+	// Wait until the request can be sent without exceeding the rate limit
+	if err := c.waitRateLimit(ctx, req); err != nil {
+		return nil, err
+	}
 
-		// Send the message
-		c.connMutex.RLock()
-		defer c.connMutex.RUnlock()
+	// Create a channel for the response
+	resultChan := make(chan requestResult, 1)
 
-		if !c.isConnected {
-			errChan <- fmt.Errorf("not connected")
-			return
-		}
+	seq := c.prepareRequest(req)
 
-		if err := c.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
-			errChan <- fmt.Errorf("failed to send request: %w", err)
-			return
-		}
+	// Register the pending request
+	c.seqCallbackMutex.Lock()
+	c.seqCallbacks[seq] = resultChan
+	c.seqCallbackMutex.Unlock()
 
-		c.emitEvent(EventRequest, req, nil)
+	// Make sure the callback does not outlive the request
+	defer func() {
+		c.seqCallbackMutex.Lock()
+		delete(c.seqCallbacks, seq)
+		c.seqCallbackMutex.Unlock()
 	}()
 
-	// Wait for the response or timeout
+	// Send the request
+	if err := c.writeRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Wait for the response or cancellation
 	select {
-	case msg := <-responseChan:
-		return msg, nil
-	case err := <-errChan:
+	case result := <-resultChan:
+		return result.msg, result.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("request timed out: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("request cancelled: %w", ctx.Err())
+	case <-c.ctx.Done():
+		return nil, ErrClientClosed
+	}
+}
+
+// request sends a request and returns the validated response
+func (c *Client) request(ctx context.Context, req *proto.AppRequest) (*proto.AppResponse, error) {
+	msg, err := c.SendRequestContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateResponse(msg); err != nil {
 		return nil, err
-	case <-time.After(timeout):
-		c.seqCallbackMutex.Lock()
-		delete(c.seqCallbacks, seq)
-		c.seqCallbackMutex.Unlock()
-		return nil, fmt.Errorf("request timed out")
 	}
+
+	return msg.Response, nil
 }
 
 // GetInfo gets information about the server
 func (c *Client) GetInfo() (*proto.AppInfo, error) {
+	return c.GetInfoContext(context.Background())
+}
+
+// GetInfoContext gets information about the server using the provided context
+func (c *Client) GetInfoContext(ctx context.Context) (*proto.AppInfo, error) {
 	request := &proto.AppRequest{
 		GetInfo: &proto.AppEmpty{},
 	}
 
-	response, err := c.SendRequestAsync(request, 10*time.Second)
+	response, err := c.request(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.Response == nil || response.Response.Info == nil {
-		return nil, fmt.Errorf("invalid response")
+	if response.Info == nil {
+		return nil, ErrInvalidResponse
 	}
 
-	return response.Response.GetInfo(), nil
+	return response.GetInfo(), nil
 }
 
 // GetTime gets the current time on the server
 func (c *Client) GetTime() (*proto.AppTime, error) {
+	return c.GetTimeContext(context.Background())
+}
+
+// GetTimeContext gets the current time on the server using the provided context
+func (c *Client) GetTimeContext(ctx context.Context) (*proto.AppTime, error) {
 	request := &proto.AppRequest{
 		GetTime: &proto.AppEmpty{},
 	}
 
-	response, err := c.SendRequestAsync(request, 10*time.Second)
+	response, err := c.request(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.Response == nil || response.Response.Time == nil {
-		return nil, fmt.Errorf("invalid response")
+	if response.Time == nil {
+		return nil, ErrInvalidResponse
 	}
 
-	return response.Response.GetTime(), nil
+	return response.GetTime(), nil
 }
 
 // GetMap gets the map from the server
 func (c *Client) GetMap() (*proto.AppMap, error) {
+	return c.GetMapContext(context.Background())
+}
+
+// GetMapContext gets the map from the server using the provided context
+func (c *Client) GetMapContext(ctx context.Context) (*proto.AppMap, error) {
 	request := &proto.AppRequest{
 		GetMap: &proto.AppEmpty{},
 	}
 
-	response, err := c.SendRequestAsync(request, 10*time.Second)
+	response, err := c.request(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.Response == nil || response.Response.Map == nil {
-		return nil, fmt.Errorf("invalid response")
+	if response.Map == nil {
+		return nil, ErrInvalidResponse
 	}
 
-	return response.Response.GetMap(), nil
+	return response.GetMap(), nil
 }
 
 // GetTeamInfo gets information about the player's team
 func (c *Client) GetTeamInfo() (*proto.AppTeamInfo, error) {
+	return c.GetTeamInfoContext(context.Background())
+}
+
+// GetTeamInfoContext gets information about the player's team using the provided context
+func (c *Client) GetTeamInfoContext(ctx context.Context) (*proto.AppTeamInfo, error) {
 	request := &proto.AppRequest{
 		GetTeamInfo: &proto.AppEmpty{},
 	}
 
-	response, err := c.SendRequestAsync(request, 10*time.Second)
+	response, err := c.request(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.Response == nil || response.Response.TeamInfo == nil {
-		return nil, fmt.Errorf("invalid response")
+	if response.TeamInfo == nil {
+		return nil, ErrInvalidResponse
 	}
 
-	return response.Response.GetTeamInfo(), nil
+	return response.GetTeamInfo(), nil
 }
 
 // GetTeamChat gets the team chat
 func (c *Client) GetTeamChat() ([]*proto.AppTeamMessage, error) {
+	return c.GetTeamChatContext(context.Background())
+}
+
+// GetTeamChatContext gets the team chat using the provided context
+func (c *Client) GetTeamChatContext(ctx context.Context) ([]*proto.AppTeamMessage, error) {
 	request := &proto.AppRequest{
 		GetTeamChat: &proto.AppEmpty{},
 	}
 
-	response, err := c.SendRequestAsync(request, 10*time.Second)
+	response, err := c.request(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.Response == nil || response.Response.TeamChat == nil {
-		return nil, fmt.Errorf("invalid response")
+	if response.TeamChat == nil {
+		return nil, ErrInvalidResponse
 	}
 
-	return response.Response.GetTeamChat().GetMessages(), nil
+	return response.GetTeamChat().GetMessages(), nil
 }
 
 // SendTeamMessage sends a message to the team chat
 func (c *Client) SendTeamMessage(message string) error {
+	return c.SendTeamMessageContext(context.Background(), message)
+}
+
+// SendTeamMessageContext sends a message to the team chat using the provided context
+func (c *Client) SendTeamMessageContext(ctx context.Context, message string) error {
 	request := &proto.AppRequest{
 		SendTeamMessage: &proto.AppSendMessage{
 			Message: protobuf.String(message),
 		},
 	}
 
-	_, err := c.SendRequestAsync(request, 10*time.Second)
+	_, err := c.request(ctx, request)
 	return err
 }
 
 // GetEntityInfo gets information about an entity
 func (c *Client) GetEntityInfo(entityID uint32) (*proto.AppEntityInfo, error) {
+	return c.GetEntityInfoContext(context.Background(), entityID)
+}
+
+// GetEntityInfoContext gets information about an entity using the provided context
+func (c *Client) GetEntityInfoContext(ctx context.Context, entityID uint32) (*proto.AppEntityInfo, error) {
 	request := &proto.AppRequest{
-		EntityId: protobuf.Uint32(entityID),
+		EntityId:      protobuf.Uint32(entityID),
+		GetEntityInfo: &proto.AppEmpty{},
 	}
 
-	response, err := c.SendRequestAsync(request, 10*time.Second)
+	response, err := c.request(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.Response == nil || response.Response.EntityInfo == nil {
-		return nil, fmt.Errorf("invalid response")
+	if response.EntityInfo == nil {
+		return nil, ErrInvalidResponse
 	}
 
-	return response.Response.GetEntityInfo(), nil
+	return response.GetEntityInfo(), nil
 }
 
 // SetEntityValue sets a value on an entity
 func (c *Client) SetEntityValue(entityID uint32, value bool) error {
+	return c.SetEntityValueContext(context.Background(), entityID, value)
+}
+
+// SetEntityValueContext sets a value on an entity using the provided context
+func (c *Client) SetEntityValueContext(ctx context.Context, entityID uint32, value bool) error {
 	request := &proto.AppRequest{
 		EntityId: protobuf.Uint32(entityID),
 		SetEntityValue: &proto.AppSetEntityValue{
@@ -464,7 +657,7 @@ func (c *Client) SetEntityValue(entityID uint32, value bool) error {
 		},
 	}
 
-	_, err := c.SendRequestAsync(request, 10*time.Second)
+	_, err := c.request(ctx, request)
 	return err
 }
 
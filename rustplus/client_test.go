@@ -0,0 +1,656 @@
+package rustplus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	"github.com/chickenfresh/go-rustplus/rustplus/rustplustest"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// connect creates a client connected to the server
+func connect(t *testing.T, server *rustplustest.Server, opts ...rustplus.ClientOption) *rustplus.Client {
+	t.Helper()
+
+	client := server.Client(opts...)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// TestClient_GetInfo tests a successful request
+func TestClient_GetInfo(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	client := connect(t, server)
+
+	info, err := client.GetInfo()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.GetName() != "Test Server" {
+		t.Errorf("Expected name Test Server, got %s", info.GetName())
+	}
+}
+
+// TestClient_ServerError tests that server errors are surfaced as typed errors
+func TestClient_ServerError(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	client := connect(t, server)
+
+	err := client.SetEntityValue(42, true)
+	if !errors.Is(err, rustplus.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	var serverErr *rustplus.ServerError
+	if !errors.As(err, &serverErr) || serverErr.Message != "not_found" {
+		t.Errorf("Expected ServerError with message not_found, got %v", err)
+	}
+
+	server.FailNext(rustplustest.RequestGetTime, "rate_limit")
+	if _, err := client.GetTime(); !errors.Is(err, rustplus.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+// TestClient_ContextCancel tests that a cancelled context ends a pending request
+func TestClient_ContextCancel(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	server.SetDelay(rustplustest.RequestGetInfo, time.Second)
+	client := connect(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GetInfoContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Request was not cancelled promptly, took %v", elapsed)
+	}
+}
+
+// TestClient_Disconnect tests that pending requests fail when the connection is lost
+func TestClient_Disconnect(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	server.SetDelay(rustplustest.RequestGetInfo, time.Second)
+	client := connect(t, server, rustplus.WithReconnectPolicy(rustplus.ReconnectPolicy{}))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.Disconnect()
+	}()
+
+	if _, err := client.GetInfo(); !errors.Is(err, rustplus.ErrDisconnected) {
+		t.Fatalf("Expected ErrDisconnected, got %v", err)
+	}
+}
+
+// TestClient_Reconnect tests that entity interest is restored after reconnecting
+func TestClient_Reconnect(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	server.AddEntity(7, proto.AppEntityType_Switch, &proto.AppEntityPayload{Value: protobuf.Bool(false)})
+	client := connect(t, server, rustplus.WithReconnectPolicy(rustplus.ReconnectPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 10 * time.Millisecond,
+	}))
+
+	sw := client.Switch(7)
+	defer sw.Close()
+
+	server.Disconnect()
+
+	// The switch's entity should be requested again once reconnected
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, req := range server.Requests() {
+			if rustplustest.KindOf(req) == rustplustest.RequestGetEntityInfo && req.GetEntityId() == 7 {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Entity was not requested after reconnecting")
+}
+
+// TestSwitch_Toggle tests toggling a switch and receiving the broadcast
+func TestSwitch_Toggle(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	server.AddEntity(7, proto.AppEntityType_Switch, &proto.AppEntityPayload{Value: protobuf.Bool(false)})
+	client := connect(t, server)
+
+	sw := client.Switch(7)
+	defer sw.Close()
+
+	// Refresh reports the initial state and set the new one
+	changes := make(chan bool, 2)
+	sw.OnStateChange(func(on bool) {
+		changes <- on
+	})
+
+	if err := sw.Toggle(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Refresh reports the initial state before the switch is turned on
+	for {
+		select {
+		case on := <-changes:
+			if on {
+				if !sw.State() {
+					t.Error("Expected cached state to be on")
+				}
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for switch broadcast")
+		}
+	}
+}
+
+// TestSwitch_On tests that switching on a fresh handle updates its state
+func TestSwitch_On(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	server.AddEntity(7, proto.AppEntityType_Switch, &proto.AppEntityPayload{Value: protobuf.Bool(false)})
+	client := connect(t, server)
+
+	sw := client.Switch(7)
+	defer sw.Close()
+
+	// Either set or the server's broadcast reports the state, not both
+	changes := make(chan bool, 1)
+	sw.OnStateChange(func(on bool) {
+		changes <- on
+	})
+
+	if err := sw.On(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !sw.State() {
+		t.Error("Expected state to be on")
+	}
+	select {
+	case on := <-changes:
+		if !on {
+			t.Error("Expected handler to be told the switch is on")
+		}
+	default:
+		t.Error("Expected handler to be notified")
+	}
+}
+
+// TestSwitch_Unchanged tests that repeated states do not notify handlers
+func TestSwitch_Unchanged(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	server.AddEntity(7, proto.AppEntityType_Switch, &proto.AppEntityPayload{Value: protobuf.Bool(false)})
+	client := connect(t, server)
+
+	sw := client.Switch(7)
+	defer sw.Close()
+	if err := sw.Refresh(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	changes := make(chan bool, 3)
+	sw.OnStateChange(func(on bool) {
+		changes <- on
+	})
+
+	server.SetEntityPayload(7, &proto.AppEntityPayload{Value: protobuf.Bool(true)})
+	server.SetEntityPayload(7, &proto.AppEntityPayload{Value: protobuf.Bool(true)})
+	server.SetEntityPayload(7, &proto.AppEntityPayload{Value: protobuf.Bool(false)})
+
+	// Broadcasts are handled in order, so the last one comes after the repeat
+	var states []bool
+	for len(states) == 0 || states[len(states)-1] {
+		select {
+		case on := <-changes:
+			states = append(states, on)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for broadcasts, got %v", states)
+		}
+	}
+	if len(states) != 2 {
+		t.Errorf("Expected the handler to run once per change, got %v", states)
+	}
+}
+
+// TestClient_OnTeamMessage tests typed team message broadcasts
+func TestClient_OnTeamMessage(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	client := connect(t, server)
+
+	messages := make(chan *proto.AppTeamMessage, 1)
+	unsubscribe := client.OnTeamMessage(func(msg *proto.AppTeamMessage) {
+		messages <- msg
+	})
+	defer unsubscribe()
+
+	if err := client.SendTeamMessage("hello"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		if msg.GetMessage() != "hello" {
+			t.Errorf("Expected message hello, got %s", msg.GetMessage())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for team message")
+	}
+}
+
+// TestClient_HandlerRequest tests that a broadcast handler can make a request
+func TestClient_HandlerRequest(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	client := connect(t, server, rustplus.WithRequestTimeout(time.Second))
+
+	results := make(chan error, 1)
+	unsubscribe := client.OnTeamMessage(func(msg *proto.AppTeamMessage) {
+		_, err := client.GetInfo()
+		results <- err
+	})
+	defer unsubscribe()
+
+	if err := client.SendTeamMessage("hello"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case err := <-results:
+		if err != nil {
+			t.Errorf("Expected the request to succeed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the handler")
+	}
+}
+
+// TestTeamTracker_Start tests that a failed start can be retried and that
+// Stop closes the changes channel
+func TestTeamTracker_Start(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	client := connect(t, server)
+	tracker := rustplus.NewTeamTracker(client)
+
+	server.FailNext(rustplustest.RequestGetTeamInfo, "no_team")
+	if err := tracker.Start(context.Background()); err == nil {
+		t.Fatal("Expected the initial fetch to fail")
+	}
+	if err := tracker.Start(context.Background()); err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+
+	tracker.Stop()
+	select {
+	case _, ok := <-tracker.Changes():
+		if ok {
+			t.Error("Expected no changes")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the changes channel to be closed")
+	}
+}
+
+// TestSubscriptionManager_Reconcile tests reconciling entity subscriptions
+func TestSubscriptionManager_Reconcile(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	server.AddEntity(1, proto.AppEntityType_Alarm, nil)
+	server.AddEntity(2, proto.AppEntityType_StorageMonitor, nil)
+	client := connect(t, server)
+
+	if err := client.SetSubscription(2, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	manager := rustplus.NewSubscriptionManager(client)
+	manager.Subscribe(1)
+	manager.Unsubscribe(2)
+	manager.Subscribe(3)
+
+	statuses, err := manager.Reconcile(context.Background())
+	if !errors.Is(err, rustplus.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for the missing entity, got %v", err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 statuses, got %d", len(statuses))
+	}
+	if !statuses[0].Changed || !statuses[0].Subscribed {
+		t.Errorf("Expected entity 1 to be subscribed, got %+v", statuses[0])
+	}
+	if !statuses[1].Changed || statuses[1].Subscribed {
+		t.Errorf("Expected entity 2 to be unsubscribed, got %+v", statuses[1])
+	}
+
+	for entityID, expected := range map[uint32]bool{1: true, 2: false} {
+		subscribed, err := client.CheckSubscription(entityID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if subscribed != expected {
+			t.Errorf("Expected entity %d subscribed=%v, got %v", entityID, expected, subscribed)
+		}
+	}
+}
+
+// nexusInfo creates the server info of a Nexus zone
+func nexusInfo(name, zone string) *proto.AppInfo {
+	return &proto.AppInfo{
+		Name:        protobuf.String(name),
+		HeaderImage: protobuf.String(""),
+		Url:         protobuf.String(""),
+		Map:         protobuf.String("Procedural Map"),
+		MapSize:     protobuf.Uint32(4000),
+		WipeTime:    protobuf.Uint32(0),
+		Players:     protobuf.Uint32(0),
+		MaxPlayers:  protobuf.Uint32(100),
+		NexusZone:   protobuf.String(zone),
+	}
+}
+
+// TestNexusClient_Zone tests routing requests to another Nexus zone
+func TestNexusClient_Zone(t *testing.T) {
+	home := rustplustest.NewServer()
+	defer home.Close()
+	home.SetInfo(nexusInfo("Home", "west"))
+
+	east := rustplustest.NewServer()
+	defer east.Close()
+	east.PlayerToken = 42
+	east.SetInfo(nexusInfo("East", "east"))
+	home.SetNexusAuth("east-key", &proto.AppNexusAuth{ServerId: protobuf.String("east"), PlayerToken: protobuf.Int32(42)})
+
+	nexus := rustplus.NewNexusClient(home.Host(), home.Port(), home.PlayerID, home.PlayerToken, false)
+	defer nexus.Close()
+
+	if err := nexus.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if nexus.HomeZone() != "west" {
+		t.Errorf("Expected home zone west, got %s", nexus.HomeZone())
+	}
+
+	if _, err := nexus.Zone(context.Background(), "north"); !errors.Is(err, rustplus.ErrUnknownZone) {
+		t.Errorf("Expected ErrUnknownZone, got %v", err)
+	}
+
+	nexus.AddZone(rustplus.NexusZone{Name: "east", AppKey: "east-key", Server: east.Host(), Port: east.Port()})
+	err := nexus.Do(context.Background(), "east", func(client *rustplus.Client) error {
+		info, err := client.GetInfo()
+		if err != nil {
+			return err
+		}
+		if info.GetName() != "East" {
+			t.Errorf("Expected name East, got %s", info.GetName())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// TestNexusClient_ConnectCanceled tests that connecting honours the context
+func TestNexusClient_ConnectCanceled(t *testing.T) {
+	home := rustplustest.NewServer()
+	defer home.Close()
+
+	nexus := rustplus.NewNexusClient(home.Host(), home.Port(), home.PlayerID, home.PlayerToken, false)
+	defer nexus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := nexus.Connect(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if home.Connections() != 0 {
+		t.Errorf("Expected no connection, got %d", home.Connections())
+	}
+}
+
+// TestNexusClient_SlowZone tests that connecting to a slow zone does not
+// hold the client's lock
+func TestNexusClient_SlowZone(t *testing.T) {
+	home := rustplustest.NewServer()
+	defer home.Close()
+	home.SetInfo(nexusInfo("Home", "west"))
+	home.SetNexusAuth("east-key", &proto.AppNexusAuth{ServerId: protobuf.String("east"), PlayerToken: protobuf.Int32(42)})
+	home.SetDelay(rustplustest.RequestGetNexusAuth, 300*time.Millisecond)
+
+	east := rustplustest.NewServer()
+	defer east.Close()
+	east.PlayerToken = 42
+
+	nexus := rustplus.NewNexusClient(home.Host(), home.Port(), home.PlayerID, home.PlayerToken, false)
+	defer nexus.Close()
+	if err := nexus.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	nexus.AddZone(rustplus.NexusZone{Name: "east", AppKey: "east-key", Server: east.Host(), Port: east.Port()})
+
+	connected := make(chan error, 1)
+	go func() {
+		_, err := nexus.Zone(context.Background(), "east")
+		connected <- err
+	}()
+
+	// A caller joining the connection in progress gives up with its own context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := nexus.Zone(ctx, "east"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if nexus.Zones(); time.Since(start) > 200*time.Millisecond {
+		t.Error("Expected the client not to be locked while connecting")
+	}
+
+	if err := <-connected; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// TestManager_Servers tests that the manager connects to each server and tags their events
+func TestManager_Servers(t *testing.T) {
+	first := rustplustest.NewServer()
+	defer first.Close()
+
+	second := rustplustest.NewServer()
+	defer second.Close()
+	second.PlayerToken = 42
+
+	manager := rustplus.NewManager()
+	defer manager.Close()
+
+	if _, err := manager.Add(rustplus.ServerConfig{Server: first.Host(), Port: first.Port(), PlayerID: first.PlayerID, PlayerToken: first.PlayerToken}); err != nil {
+		t.Fatalf("Failed to add server: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"server":"%s:%d","playerId":"%d","playerToken":"%d"}`, second.Host(), second.Port(), second.PlayerID, second.PlayerToken)
+	pairing, err := rustplus.ParsePairing(map[string]interface{}{"data": map[string]interface{}{"body": body}})
+	if err != nil {
+		t.Fatalf("Failed to parse pairing: %v", err)
+	}
+	if _, err := manager.HandlePairing(pairing); err != nil {
+		t.Fatalf("Failed to handle pairing: %v", err)
+	}
+
+	firstKey := rustplus.ServerKey(first.Host(), first.Port())
+	secondKey := rustplus.ServerKey(second.Host(), second.Port())
+	if servers := manager.Servers(); len(servers) != 2 {
+		t.Fatalf("Expected 2 servers, got %v", servers)
+	}
+
+	// Wait for both clients to connect
+	connected := make(map[string]bool)
+	timeout := time.After(2 * time.Second)
+	for len(connected) < 2 {
+		select {
+		case event := <-manager.Events():
+			if event.Type == rustplus.EventConnected {
+				connected[event.Server] = true
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for connections, connected to %v", connected)
+		}
+	}
+	if !connected[firstKey] || !connected[secondKey] {
+		t.Fatalf("Expected events from both servers, got %v", connected)
+	}
+
+	if err := manager.Remove(firstKey); err != nil {
+		t.Fatalf("Failed to remove server: %v", err)
+	}
+	if _, ok := manager.Client(firstKey); ok {
+		t.Error("Expected removed server to be gone")
+	}
+	if err := manager.Remove(firstKey); !errors.Is(err, rustplus.ErrServerNotManaged) {
+		t.Errorf("Expected ErrServerNotManaged, got %v", err)
+	}
+
+	client, ok := manager.Client(secondKey)
+	if !ok {
+		t.Fatal("Expected second server to be managed")
+	}
+	if _, err := client.GetInfo(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestManager_CloseConnecting tests that closing the manager gives up on a
+// server that never answers
+func TestManager_CloseConnecting(t *testing.T) {
+	// The listener's backlog completes the TCP handshake, but the WebSocket
+	// handshake is never answered
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	addr := listener.Addr().(*net.TCPAddr)
+
+	manager := rustplus.NewManager()
+	if _, err := manager.Add(rustplus.ServerConfig{Server: addr.IP.String(), Port: addr.Port, PlayerID: 1, PlayerToken: 1}); err != nil {
+		t.Fatalf("Failed to add server: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() { closed <- manager.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the manager to close")
+	}
+}
+
+// TestCamera_UnsubscribeFailed tests that a camera can be subscribed to again
+// after unsubscribing failed
+func TestCamera_UnsubscribeFailed(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+	server.AddCamera("CAM1", &proto.AppCameraInfo{
+		Width:        protobuf.Int32(160),
+		Height:       protobuf.Int32(90),
+		NearPlane:    protobuf.Float32(0),
+		FarPlane:     protobuf.Float32(250),
+		ControlFlags: protobuf.Int32(0),
+	})
+
+	client := connect(t, server)
+	camera := rustplus.NewCamera(client, "CAM1")
+
+	if err := camera.Subscribe(); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	server.FailNext(rustplustest.RequestCameraUnsubscribe, "not_found")
+	if err := camera.Unsubscribe(); err == nil {
+		t.Error("Expected unsubscribing to fail")
+	}
+	if err := camera.Subscribe(); err != nil {
+		t.Errorf("Failed to subscribe again: %v", err)
+	}
+}
+
+// serverTime creates a server time at the given in-game hour, with sunset at 20:00
+func serverTime(hour float32) *proto.AppTime {
+	return &proto.AppTime{
+		DayLengthMinutes: protobuf.Float32(60),
+		TimeScale:        protobuf.Float32(1),
+		Sunrise:          protobuf.Float32(7),
+		Sunset:           protobuf.Float32(20),
+		Time:             protobuf.Float32(hour),
+	}
+}
+
+// TestDayNightTicker tests that the ticker reports sunset once the server's
+// clock passes it and stops when asked
+func TestDayNightTicker(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+	server.SetTime(serverTime(19))
+
+	client := connect(t, server)
+
+	ticker := rustplus.NewDayNightTicker(client)
+	ticker.Resync = 20 * time.Millisecond
+	if err := ticker.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	// The clock jumps past sunset, as after the server skips time
+	server.SetTime(serverTime(20.5))
+	select {
+	case event := <-ticker.Events():
+		if event.Phase != rustplus.PhaseDusk || event.Time.IsDay() {
+			t.Errorf("Expected dusk, got %s at %s", event.Phase, event.Time.Clock())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for dusk")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		ticker.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the ticker to stop")
+	}
+}
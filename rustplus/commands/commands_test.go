@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// recorder is a Sender that records the messages sent
+type recorder struct {
+	messages []string
+	mutex    sync.Mutex
+}
+
+func (r *recorder) SendTeamMessageContext(ctx context.Context, message string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+// last returns the last message sent
+func (r *recorder) last() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.messages) == 0 {
+		return ""
+	}
+	return r.messages[len(r.messages)-1]
+}
+
+// message creates a team message from a player
+func message(steamID uint64, text string) *proto.AppTeamMessage {
+	return &proto.AppTeamMessage{
+		SteamId: protobuf.Uint64(steamID),
+		Name:    protobuf.String("Player"),
+		Message: protobuf.String(text),
+	}
+}
+
+// TestRouter_Handle tests routing, permissions, cooldowns and help
+func TestRouter_Handle(t *testing.T) {
+	sender := &recorder{}
+	router := NewRouter(sender)
+	ctx := context.Background()
+
+	var switched []string
+	router.MustRegister(Command{
+		Name:        "switch",
+		Aliases:     []string{"sw"},
+		Usage:       "<on|off> <name>",
+		Description: "Turns a switch on or off",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Permission:  "switch",
+		Cooldown:    time.Minute,
+		Handler: func(ctx context.Context, req *Request) error {
+			if req.Args[0] != "on" && req.Args[0] != "off" {
+				return ErrUsage
+			}
+			switched = append(switched, strings.Join(req.Args, " "))
+			return req.Reply(ctx, "Done")
+		},
+	})
+	router.Permissions.Grant(1, "switch")
+
+	if ok, _ := router.Handle(ctx, message(1, "hello")); ok {
+		t.Error("Expected plain messages to be ignored")
+	}
+
+	router.Handle(ctx, message(2, "!switch on base"))
+	if got := sender.last(); !strings.Contains(got, "permission") {
+		t.Errorf("Expected a permission error, got %q", got)
+	}
+
+	router.Handle(ctx, message(1, "!sw up base"))
+	if got := sender.last(); got != "Usage: !switch <on|off> <name>" {
+		t.Errorf("Expected usage, got %q", got)
+	}
+
+	router.Handle(ctx, message(1, `!SW on "main base"`))
+	if !reflect.DeepEqual(switched, []string{"on main base"}) {
+		t.Errorf("Unexpected switch calls %v", switched)
+	}
+
+	// The reply comes back as a team message and must not be handled again
+	if ok, _ := router.Handle(ctx, message(1, "Done")); ok {
+		t.Error("Expected the router's own reply to be ignored")
+	}
+
+	router.Handle(ctx, message(1, "!switch off base"))
+	if got := sender.last(); !strings.Contains(got, "cooldown") {
+		t.Errorf("Expected a cooldown message, got %q", got)
+	}
+
+	router.Handle(ctx, message(2, "!help"))
+	if got := sender.last(); got != "Commands: !help" {
+		t.Errorf("Expected help without the switch command, got %q", got)
+	}
+
+	router.Handle(ctx, message(1, "!help"))
+	if got := sender.last(); got != "Commands: !help !switch" {
+		t.Errorf("Expected help with the switch command, got %q", got)
+	}
+
+	// Any space separates the name from the arguments
+	for _, text := range []string{"!help\tsw", "!help\u00a0sw"} {
+		router.Handle(ctx, message(1, text))
+		if got := sender.last(); !strings.HasPrefix(got, "!switch <on|off> <name>") {
+			t.Errorf("Expected help for the switch command from %q, got %q", text, got)
+		}
+	}
+}
+
+// TestParseArgs tests splitting quoted arguments
+func TestParseArgs(t *testing.T) {
+	args, err := ParseArgs(`on "main base" it\'s 'a b'`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"on", "main base", "it's", "a b"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("ParseArgs = %q, want %q", args, want)
+	}
+
+	if _, err := ParseArgs(`"open`); err == nil {
+		t.Error("Expected an error for an unterminated quote")
+	}
+}
+
+// TestSplitMessage tests splitting long messages
+func TestSplitMessage(t *testing.T) {
+	parts := SplitMessage("one two three\nfour abcdefghij", 8)
+	want := []string{"one two", "three", "four", "abcdefgh", "ij"}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("SplitMessage = %q, want %q", parts, want)
+	}
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"sync"
+	"time"
+)
+
+// Permissions grants named permissions to players by Steam ID. Admins hold
+// every permission.
+type Permissions struct {
+	admins map[uint64]bool
+	grants map[uint64]map[string]bool
+	mutex  sync.RWMutex
+}
+
+// NewPermissions creates an empty permission set
+func NewPermissions() *Permissions {
+	return &Permissions{
+		admins: make(map[uint64]bool),
+		grants: make(map[uint64]map[string]bool),
+	}
+}
+
+// AddAdmin gives players every permission
+func (p *Permissions) AddAdmin(steamIDs ...uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, steamID := range steamIDs {
+		p.admins[steamID] = true
+	}
+}
+
+// RemoveAdmin takes admin rights away from players
+func (p *Permissions) RemoveAdmin(steamIDs ...uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, steamID := range steamIDs {
+		delete(p.admins, steamID)
+	}
+}
+
+// Grant gives a player permissions
+func (p *Permissions) Grant(steamID uint64, permissions ...string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.grants[steamID] == nil {
+		p.grants[steamID] = make(map[string]bool)
+	}
+	for _, permission := range permissions {
+		p.grants[steamID][permission] = true
+	}
+}
+
+// Revoke takes permissions away from a player
+func (p *Permissions) Revoke(steamID uint64, permissions ...string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, permission := range permissions {
+		delete(p.grants[steamID], permission)
+	}
+}
+
+// Allowed reports whether a player holds a permission
+func (p *Permissions) Allowed(steamID uint64, permission string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.admins[steamID] || p.grants[steamID][permission]
+}
+
+// cooldownKey identifies a cooldown. A zero Steam ID is the global cooldown.
+type cooldownKey struct {
+	command *Command
+	steamID uint64
+}
+
+// cooldowns tracks when commands may next be used
+type cooldowns struct {
+	until map[cooldownKey]time.Time
+	mutex sync.Mutex
+}
+
+// newCooldowns creates an empty cooldown tracker
+func newCooldowns() *cooldowns {
+	return &cooldowns{until: make(map[cooldownKey]time.Time)}
+}
+
+// take starts the cooldowns of a command for a player, or returns how long
+// they must still wait
+func (c *cooldowns) take(cmd *Command, steamID uint64, now time.Time) time.Duration {
+	if cmd.Cooldown <= 0 && cmd.GlobalCooldown <= 0 {
+		return 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	player := cooldownKey{command: cmd, steamID: steamID}
+	global := cooldownKey{command: cmd}
+
+	wait := c.until[player].Sub(now)
+	if globalWait := c.until[global].Sub(now); globalWait > wait {
+		wait = globalWait
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if cmd.Cooldown > 0 {
+		c.until[player] = now.Add(cmd.Cooldown)
+	}
+	if cmd.GlobalCooldown > 0 {
+		c.until[global] = now.Add(cmd.GlobalCooldown)
+	}
+
+	// Forget cooldowns that have run out
+	for key, until := range c.until {
+		if !until.After(now) {
+			delete(c.until, key)
+		}
+	}
+
+	return 0
+}
+
+// release ends the cooldowns of a command for a player, used when the
+// command was not actually run
+func (c *cooldowns) release(cmd *Command, steamID uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.until, cooldownKey{command: cmd, steamID: steamID})
+	delete(c.until, cooldownKey{command: cmd})
+}
@@ -0,0 +1,332 @@
+// Package commands routes team chat messages such as "!switch on base" to
+// command handlers and sends their replies back to team chat.
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// DefaultPrefix is the prefix commands start with
+const DefaultPrefix = "!"
+
+// ErrUsage is returned by handlers when the arguments are invalid. The
+// router replies with the command's usage.
+var ErrUsage = errors.New("invalid usage")
+
+// Sender sends messages to team chat. *rustplus.Client implements it.
+type Sender interface {
+	SendTeamMessageContext(ctx context.Context, message string) error
+}
+
+// Handler runs a command
+type Handler func(ctx context.Context, req *Request) error
+
+// Command describes a chat command
+type Command struct {
+	// Name is what follows the prefix, such as "pop" for "!pop"
+	Name    string
+	Aliases []string
+	// Usage describes the arguments, such as "<on|off> <name>"
+	Usage       string
+	Description string
+	// MinArgs and MaxArgs bound the number of arguments. A MaxArgs of zero
+	// allows any number of arguments.
+	MinArgs int
+	MaxArgs int
+	// Permission is required to run the command. An empty permission lets
+	// anyone in the team run it.
+	Permission string
+	// Cooldown is how long each player must wait between uses
+	Cooldown time.Duration
+	// GlobalCooldown is how long everyone must wait between uses
+	GlobalCooldown time.Duration
+	// Hidden leaves the command out of the help
+	Hidden  bool
+	Handler Handler
+}
+
+// Request is a command sent in team chat
+type Request struct {
+	Command *Command
+	// Name is the command name or alias that was used
+	Name    string
+	Args    []string
+	RawArgs string
+	SteamID uint64
+	Player  string
+	Message *proto.AppTeamMessage
+	router  *Router
+}
+
+// Reply sends a message to team chat, split to respect the chat length limit
+func (r *Request) Reply(ctx context.Context, message string) error {
+	return r.router.Send(ctx, message)
+}
+
+// Replyf formats and sends a message to team chat
+func (r *Request) Replyf(ctx context.Context, format string, args ...interface{}) error {
+	return r.Reply(ctx, fmt.Sprintf(format, args...))
+}
+
+// Router dispatches team chat messages to commands
+type Router struct {
+	// Prefix is what commands start with
+	Prefix string
+	// MaxLength is the longest message sent to team chat
+	MaxLength int
+	// Permissions decides who may run commands that need a permission
+	Permissions *Permissions
+	// OnError is called when a handler fails with an error other than
+	// ErrUsage. By default the error is sent to team chat.
+	OnError func(ctx context.Context, req *Request, err error)
+
+	sender   Sender
+	commands map[string]*Command
+	names    map[string]*Command
+	cooldown *cooldowns
+	sent     map[string]int
+	mutex    sync.RWMutex
+}
+
+// NewRouter creates a router that replies with sender. It has a built-in
+// help command.
+func NewRouter(sender Sender) *Router {
+	r := &Router{
+		Prefix:      DefaultPrefix,
+		MaxLength:   MaxMessageLength,
+		Permissions: NewPermissions(),
+		sender:      sender,
+		commands:    make(map[string]*Command),
+		names:       make(map[string]*Command),
+		cooldown:    newCooldowns(),
+		sent:        make(map[string]int),
+	}
+
+	r.MustRegister(Command{
+		Name:        "help",
+		Usage:       "[command]",
+		Description: "Lists commands or shows how to use one",
+		MaxArgs:     1,
+		Handler:     r.help,
+	})
+
+	return r
+}
+
+// Register adds a command
+func (r *Router) Register(cmd Command) error {
+	if cmd.Name == "" || cmd.Handler == nil {
+		return fmt.Errorf("command must have a name and a handler")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	for _, name := range names {
+		if _, ok := r.names[strings.ToLower(name)]; ok {
+			return fmt.Errorf("command %q already registered", name)
+		}
+	}
+
+	c := &cmd
+	r.commands[strings.ToLower(cmd.Name)] = c
+	for _, name := range names {
+		r.names[strings.ToLower(name)] = c
+	}
+
+	return nil
+}
+
+// MustRegister adds a command and panics if it cannot be registered
+func (r *Router) MustRegister(cmd Command) {
+	if err := r.Register(cmd); err != nil {
+		panic(err)
+	}
+}
+
+// Commands returns the registered commands sorted by name
+func (r *Router) Commands() []*Command {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	commands := make([]*Command, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		commands = append(commands, cmd)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// Lookup returns the command with the given name or alias
+func (r *Router) Lookup(name string) (*Command, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	cmd, ok := r.names[strings.ToLower(name)]
+	return cmd, ok
+}
+
+// Attach routes the client's team chat messages and returns a function that
+// detaches the router. Commands run in their own goroutine, so a slow command
+// does not hold up the client's other handlers.
+func (r *Router) Attach(ctx context.Context, client *rustplus.Client) func() {
+	return client.OnTeamMessage(func(msg *proto.AppTeamMessage) {
+		go func() {
+			if _, err := r.Handle(ctx, msg); err != nil {
+				fmt.Printf("Warning: Command failed: %v\n", err)
+			}
+		}()
+	})
+}
+
+// Handle runs the command in a team chat message. It reports whether the
+// message was a command.
+func (r *Router) Handle(ctx context.Context, msg *proto.AppTeamMessage) (bool, error) {
+	text := strings.TrimSpace(msg.GetMessage())
+
+	// Replies sent by the router come back as team messages too
+	if r.consumeSent(text) || !strings.HasPrefix(text, r.Prefix) {
+		return false, nil
+	}
+
+	// The name ends at the first space of any kind, as arguments do
+	name, rawArgs := strings.TrimPrefix(text, r.Prefix), ""
+	if i := strings.IndexFunc(name, unicode.IsSpace); i >= 0 {
+		name, rawArgs = name[:i], name[i:]
+	}
+	cmd, ok := r.Lookup(name)
+	if !ok {
+		return false, nil
+	}
+
+	args, err := ParseArgs(rawArgs)
+	req := &Request{
+		Command: cmd,
+		Name:    name,
+		Args:    args,
+		RawArgs: strings.TrimSpace(rawArgs),
+		SteamID: msg.GetSteamId(),
+		Player:  msg.GetName(),
+		Message: msg,
+		router:  r,
+	}
+	if err != nil {
+		return true, r.usage(ctx, req)
+	}
+
+	if cmd.Permission != "" && !r.Permissions.Allowed(req.SteamID, cmd.Permission) {
+		return true, req.Replyf(ctx, "%s: you don't have permission to use %s%s", req.Player, r.Prefix, cmd.Name)
+	}
+
+	if len(args) < cmd.MinArgs || (cmd.MaxArgs > 0 && len(args) > cmd.MaxArgs) {
+		return true, r.usage(ctx, req)
+	}
+
+	if wait := r.cooldown.take(cmd, req.SteamID, time.Now()); wait > 0 {
+		return true, req.Replyf(ctx, "%s%s is on cooldown for %s", r.Prefix, cmd.Name, wait.Round(time.Second))
+	}
+
+	if err := cmd.Handler(ctx, req); err != nil {
+		if errors.Is(err, ErrUsage) {
+			r.cooldown.release(cmd, req.SteamID)
+			return true, r.usage(ctx, req)
+		}
+
+		if r.OnError != nil {
+			r.OnError(ctx, req, err)
+			return true, nil
+		}
+		return true, req.Replyf(ctx, "%s%s failed: %v", r.Prefix, cmd.Name, err)
+	}
+
+	return true, nil
+}
+
+// Send sends a message to team chat, split to respect MaxLength
+func (r *Router) Send(ctx context.Context, message string) error {
+	for _, part := range SplitMessage(message, r.MaxLength) {
+		r.mutex.Lock()
+		r.sent[part]++
+		r.mutex.Unlock()
+
+		if err := r.sender.SendTeamMessageContext(ctx, part); err != nil {
+			r.consumeSent(part)
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+	return nil
+}
+
+// consumeSent reports whether a message was sent by the router and forgets it
+func (r *Router) consumeSent(message string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.sent[message] == 0 {
+		return false
+	}
+
+	r.sent[message]--
+	if r.sent[message] == 0 {
+		delete(r.sent, message)
+	}
+	return true
+}
+
+// usage replies with how to use a command
+func (r *Router) usage(ctx context.Context, req *Request) error {
+	return req.Reply(ctx, "Usage: "+r.usageOf(req.Command))
+}
+
+// usageOf returns the prefixed name and arguments of a command
+func (r *Router) usageOf(cmd *Command) string {
+	if cmd.Usage == "" {
+		return r.Prefix + cmd.Name
+	}
+	return r.Prefix + cmd.Name + " " + cmd.Usage
+}
+
+// help lists the commands the player may run, or describes one command
+func (r *Router) help(ctx context.Context, req *Request) error {
+	if len(req.Args) == 1 {
+		cmd, ok := r.Lookup(strings.TrimPrefix(req.Args[0], r.Prefix))
+		if !ok || cmd.Hidden {
+			return req.Replyf(ctx, "Unknown command %s", req.Args[0])
+		}
+
+		help := r.usageOf(cmd)
+		if cmd.Description != "" {
+			help += " - " + cmd.Description
+		}
+		if len(cmd.Aliases) > 0 {
+			help += " (aliases: " + strings.Join(cmd.Aliases, ", ") + ")"
+		}
+		return req.Reply(ctx, help)
+	}
+
+	var names []string
+	for _, cmd := range r.Commands() {
+		if cmd.Hidden {
+			continue
+		}
+		if cmd.Permission != "" && !r.Permissions.Allowed(req.SteamID, cmd.Permission) {
+			continue
+		}
+		names = append(names, r.Prefix+cmd.Name)
+	}
+
+	return req.Reply(ctx, "Commands: "+strings.Join(names, " "))
+}
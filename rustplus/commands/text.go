@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxMessageLength is the longest team chat message the server accepts
+const MaxMessageLength = 128
+
+// ParseArgs splits arguments on whitespace. Single or double quotes group
+// words into one argument and a backslash escapes the next character.
+func ParseArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// SplitMessage splits a message into parts of at most max characters,
+// breaking on newlines and then between words where possible
+func SplitMessage(message string, max int) []string {
+	if max <= 0 {
+		max = MaxMessageLength
+	}
+
+	var parts []string
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var current []rune
+		for _, word := range strings.Fields(line) {
+			runes := []rune(word)
+
+			// Start a new part if the word does not fit in the current one
+			if len(current) > 0 && len(current)+1+len(runes) > max {
+				parts = append(parts, string(current))
+				current = nil
+			}
+
+			// Break words that are longer than a whole part
+			for len(runes) > max {
+				parts = append(parts, string(runes[:max]))
+				runes = runes[max:]
+			}
+
+			if len(current) > 0 {
+				current = append(current, ' ')
+			}
+			current = append(current, runes...)
+		}
+
+		if len(current) > 0 {
+			parts = append(parts, string(current))
+		}
+	}
+
+	return parts
+}
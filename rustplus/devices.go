@@ -0,0 +1,277 @@
+package rustplus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// device holds the cached state shared by the smart device handles. The
+// cache is kept up to date from EntityChanged broadcasts, which the server
+// only sends after the entity has been requested with Refresh.
+type device struct {
+	client        *Client
+	entityID      uint32
+	entityType    proto.AppEntityType
+	payload       *proto.AppEntityPayload
+	mutex         sync.RWMutex
+	removeHandler func()
+	handlers      handlerList[func(*proto.AppEntityPayload)]
+	// changed reports whether a new payload changes the state the handle
+	// exposes, so handlers are not called for repeated states
+	changed func(previous, payload *proto.AppEntityPayload) bool
+}
+
+// newDevice creates a device and starts tracking its broadcasts
+func newDevice(client *Client, entityID uint32, entityType proto.AppEntityType, changed func(previous, payload *proto.AppEntityPayload) bool) *device {
+	d := &device{
+		client:     client,
+		entityID:   entityID,
+		entityType: entityType,
+		changed:    changed,
+	}
+
+	d.removeHandler = client.OnEntityChanged(entityID, func(changed *proto.AppEntityChanged) {
+		d.update(changed.GetPayload())
+	})
+
+	return d
+}
+
+// ID returns the entity ID of the device
+func (d *device) ID() uint32 {
+	return d.entityID
+}
+
+// Refresh fetches the current state of the device from the server
+func (d *device) Refresh(ctx context.Context) error {
+	info, err := d.client.GetEntityInfoContext(ctx, d.entityID)
+	if err != nil {
+		return err
+	}
+
+	if info.GetType() != d.entityType {
+		return fmt.Errorf("entity %d is a %s, not a %s: %w", d.entityID, info.GetType(), d.entityType, ErrWrongType)
+	}
+
+	d.update(info.GetPayload())
+	return nil
+}
+
+// Close stops tracking broadcasts for the device
+func (d *device) Close() {
+	d.removeHandler()
+}
+
+// update replaces the cached payload and notifies the device's handlers if
+// the state is new or has changed
+func (d *device) update(payload *proto.AppEntityPayload) {
+	d.mutex.Lock()
+	notify := d.payload == nil || d.changed(d.payload, payload)
+	d.payload = payload
+	d.mutex.Unlock()
+
+	if notify {
+		dispatch(&d.handlers, payload)
+	}
+}
+
+// cached returns the cached payload, or nil if the state is not yet known
+func (d *device) cached() *proto.AppEntityPayload {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.payload
+}
+
+// Switch is a handle to a Smart Switch
+type Switch struct {
+	*device
+}
+
+// Switch returns a handle to the Smart Switch with the given entity ID
+func (c *Client) Switch(entityID uint32) *Switch {
+	return &Switch{device: newDevice(c, entityID, proto.AppEntityType_Switch, valueChanged)}
+}
+
+// On turns the switch on
+func (s *Switch) On(ctx context.Context) error {
+	return s.set(ctx, true)
+}
+
+// Off turns the switch off
+func (s *Switch) Off(ctx context.Context) error {
+	return s.set(ctx, false)
+}
+
+// Toggle flips the switch, fetching its state first if it is not yet known
+func (s *Switch) Toggle(ctx context.Context) error {
+	if s.cached() == nil {
+		if err := s.Refresh(ctx); err != nil {
+			return err
+		}
+	}
+
+	return s.set(ctx, !s.State())
+}
+
+// State returns the cached on/off state of the switch
+func (s *Switch) State() bool {
+	return s.cached().GetValue()
+}
+
+// OnStateChange registers a handler called with the state once it is known
+// and whenever the switch changes, and returns a function that removes it
+func (s *Switch) OnStateChange(handler func(on bool)) func() {
+	return s.handlers.add(func(payload *proto.AppEntityPayload) {
+		handler(payload.GetValue())
+	})
+}
+
+// set sets the value of the switch, updates the cached state and notifies
+// the switch's handlers if it changed
+func (s *Switch) set(ctx context.Context, value bool) error {
+	if err := s.client.SetEntityValueContext(ctx, s.entityID, value); err != nil {
+		return err
+	}
+
+	// The payload may be shared with other handlers, so update a copy
+	payload := &proto.AppEntityPayload{}
+	if cached := s.cached(); cached != nil {
+		payload = protobuf.Clone(cached).(*proto.AppEntityPayload)
+	}
+	payload.Value = Bool(value)
+
+	s.update(payload)
+	return nil
+}
+
+// valueChanged reports whether the on/off value of a switch or alarm changed
+func valueChanged(previous, payload *proto.AppEntityPayload) bool {
+	return previous.GetValue() != payload.GetValue()
+}
+
+// Alarm is a handle to a Smart Alarm
+type Alarm struct {
+	*device
+}
+
+// Alarm returns a handle to the Smart Alarm with the given entity ID
+func (c *Client) Alarm(entityID uint32) *Alarm {
+	return &Alarm{device: newDevice(c, entityID, proto.AppEntityType_Alarm, valueChanged)}
+}
+
+// Triggered returns the cached triggered state of the alarm
+func (a *Alarm) Triggered() bool {
+	return a.cached().GetValue()
+}
+
+// OnTriggered registers a handler called with the triggered state once it is
+// known and whenever it changes, and returns a function that removes it
+func (a *Alarm) OnTriggered(handler func(triggered bool)) func() {
+	return a.handlers.add(func(payload *proto.AppEntityPayload) {
+		handler(payload.GetValue())
+	})
+}
+
+// StorageItem represents a stack of items in a storage container
+type StorageItem struct {
+	ItemID      int32
+	Quantity    int32
+	IsBlueprint bool
+}
+
+// String describes the stack using the default item catalogue, such as
+// "Sulfur Ore x 20000"
+func (i StorageItem) String() string {
+	return formatItem(i.ItemID, i.Quantity, i.IsBlueprint)
+}
+
+// StorageContents is a snapshot of the container watched by a Storage Monitor
+type StorageContents struct {
+	Items    []StorageItem
+	Capacity int32
+	// HasProtection is set when the container is a Tool Cupboard with upkeep
+	HasProtection bool
+	// ProtectionExpiry is when the Tool Cupboard's upkeep runs out, or the
+	// zero time if the container is not protected
+	ProtectionExpiry time.Time
+}
+
+// equal reports whether two snapshots hold the same contents
+func (c StorageContents) equal(other StorageContents) bool {
+	return c.Capacity == other.Capacity &&
+		c.HasProtection == other.HasProtection &&
+		c.ProtectionExpiry.Equal(other.ProtectionExpiry) &&
+		slices.Equal(c.Items, other.Items)
+}
+
+// newStorageContents converts an entity payload into storage contents
+func newStorageContents(payload *proto.AppEntityPayload) StorageContents {
+	contents := StorageContents{
+		Capacity:      payload.GetCapacity(),
+		HasProtection: payload.GetHasProtection(),
+	}
+
+	for _, item := range payload.GetItems() {
+		contents.Items = append(contents.Items, StorageItem{
+			ItemID:      item.GetItemId(),
+			Quantity:    item.GetQuantity(),
+			IsBlueprint: item.GetItemIsBlueprint(),
+		})
+	}
+
+	if expiry := payload.GetProtectionExpiry(); expiry > 0 {
+		contents.ProtectionExpiry = time.Unix(int64(expiry), 0)
+	}
+
+	return contents
+}
+
+// contentsChanged reports whether the contents of a monitored container changed
+func contentsChanged(previous, payload *proto.AppEntityPayload) bool {
+	return !newStorageContents(previous).equal(newStorageContents(payload))
+}
+
+// StorageMonitor is a handle to a Storage Monitor
+type StorageMonitor struct {
+	*device
+}
+
+// StorageMonitor returns a handle to the Storage Monitor with the given entity ID
+func (c *Client) StorageMonitor(entityID uint32) *StorageMonitor {
+	return &StorageMonitor{device: newDevice(c, entityID, proto.AppEntityType_StorageMonitor, contentsChanged)}
+}
+
+// Contents returns the cached contents of the monitored container
+func (m *StorageMonitor) Contents() StorageContents {
+	return newStorageContents(m.cached())
+}
+
+// Items returns the cached items in the monitored container
+func (m *StorageMonitor) Items() []StorageItem {
+	return m.Contents().Items
+}
+
+// Capacity returns the cached number of slots in the monitored container
+func (m *StorageMonitor) Capacity() int32 {
+	return m.cached().GetCapacity()
+}
+
+// ProtectionExpiry returns when the Tool Cupboard's upkeep runs out, or the
+// zero time if the container is not protected
+func (m *StorageMonitor) ProtectionExpiry() time.Time {
+	return m.Contents().ProtectionExpiry
+}
+
+// OnUpdate registers a handler called with the contents once they are known
+// and whenever the container changes, and returns a function that removes it
+func (m *StorageMonitor) OnUpdate(handler func(StorageContents)) func() {
+	return m.handlers.add(func(payload *proto.AppEntityPayload) {
+		handler(newStorageContents(payload))
+	})
+}
@@ -0,0 +1,81 @@
+package rustplus
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// Sentinel errors matching the error strings sent by the Rust+ server.
+// Use errors.Is to check a returned error against them.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrNoTeam          = errors.New("not in a team")
+	ErrNoClan          = errors.New("not in a clan")
+	ErrAccessDenied    = errors.New("access denied")
+	ErrNoPlayer        = errors.New("player not found")
+	ErrInvalidPlayerID = errors.New("invalid player ID")
+	ErrWrongType       = errors.New("wrong entity type")
+	ErrBanned          = errors.New("banned")
+	ErrInvalidMotd     = errors.New("invalid MOTD")
+	ErrMessageNotSent  = errors.New("message not sent")
+	ErrServerError     = errors.New("server error")
+)
+
+// ErrInvalidResponse is returned when the server replies with a message
+// that does not contain the expected response data
+var ErrInvalidResponse = errors.New("invalid response")
+
+// ErrDisconnected is returned for requests that were pending when the connection was lost
+var ErrDisconnected = errors.New("disconnected")
+
+// ErrClientClosed is returned for requests that were pending when the client was closed
+var ErrClientClosed = errors.New("client closed")
+
+// serverErrors maps the error strings sent by the server to sentinel errors
+var serverErrors = map[string]error{
+	"not_found":        ErrNotFound,
+	"rate_limit":       ErrRateLimited,
+	"no_team":          ErrNoTeam,
+	"no_clan":          ErrNoClan,
+	"access_denied":    ErrAccessDenied,
+	"no_player":        ErrNoPlayer,
+	"invalid_playerid": ErrInvalidPlayerID,
+	"wrong_type":       ErrWrongType,
+	"banned":           ErrBanned,
+	"invalid_motd":     ErrInvalidMotd,
+	"message_not_sent": ErrMessageNotSent,
+	"server_error":     ErrServerError,
+}
+
+// ServerError represents an error returned by the Rust+ server in AppResponse.Error
+type ServerError struct {
+	// Message is the raw error string sent by the server, e.g. "not_found"
+	Message string
+}
+
+// Error implements the error interface
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server error: %s", e.Message)
+}
+
+// Unwrap returns the sentinel error matching the server's error string, if known
+func (e *ServerError) Unwrap() error {
+	return serverErrors[e.Message]
+}
+
+// validateResponse checks a message received in reply to a request and
+// returns an error if it is not a response or the server reported an error
+func validateResponse(msg *proto.AppMessage) error {
+	if msg == nil || msg.Response == nil {
+		return ErrInvalidResponse
+	}
+
+	if msg.Response.Error != nil {
+		return &ServerError{Message: msg.Response.Error.GetError()}
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package rustplus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// ErrInvalidGridReference is returned when a grid reference cannot be parsed
+// or lies outside the map
+var ErrInvalidGridReference = errors.New("invalid grid reference")
+
+// GridCellSize is the approximate size in metres of a map grid cell. The game
+// fits as many cells of this size as possible and stretches them to fill the map.
+const GridCellSize = 146.3
+
+// Grid converts world coordinates into the grid references shown on the in-game map
+type Grid struct {
+	// MapSize is the world size of the map in metres, from AppInfo.MapSize
+	MapSize uint32
+	// Cells is the number of cells along each side of the map
+	Cells int
+	// CellSize is the size of a cell in metres
+	CellSize float32
+}
+
+// NewGrid creates the grid of a map of the given world size
+func NewGrid(mapSize uint32) Grid {
+	cells := int(math.Floor(float64(mapSize) / GridCellSize))
+	if cells < 1 {
+		cells = 1
+	}
+
+	return Grid{
+		MapSize:  mapSize,
+		Cells:    cells,
+		CellSize: float32(mapSize) / float32(cells),
+	}
+}
+
+// GridFromInfo creates the grid of the map described by GetInfo
+func GridFromInfo(info *proto.AppInfo) Grid {
+	return NewGrid(info.GetMapSize())
+}
+
+// GridCell is a cell of the map grid. Columns are lettered from the west and
+// rows numbered from the north, both starting at zero.
+type GridCell struct {
+	Column int
+	Row    int
+}
+
+// String returns the grid reference of the cell, such as "G14"
+func (c GridCell) String() string {
+	return GridColumn(c.Column) + strconv.Itoa(c.Row)
+}
+
+// Bounds is an axis-aligned rectangle in world coordinates
+type Bounds struct {
+	MinX, MinY float32
+	MaxX, MaxY float32
+}
+
+// Center returns the centre of the rectangle
+func (b Bounds) Center() (x, y float32) {
+	return (b.MinX + b.MaxX) / 2, (b.MinY + b.MaxY) / 2
+}
+
+// Contains reports whether a point lies inside the rectangle
+func (b Bounds) Contains(x, y float32) bool {
+	return x >= b.MinX && x < b.MaxX && y >= b.MinY && y < b.MaxY
+}
+
+// Cell returns the cell containing a world position. ok is false when the
+// position lies outside the map, such as out at sea.
+func (g Grid) Cell(x, y float32) (cell GridCell, ok bool) {
+	size := float32(g.MapSize)
+	if x < 0 || y < 0 || x >= size || y >= size {
+		return GridCell{}, false
+	}
+
+	// The southern edge belongs to the last row
+	cell = GridCell{
+		Column: int(x / g.CellSize),
+		Row:    int((size - y) / g.CellSize),
+	}
+	cell.Column = min(cell.Column, g.Cells-1)
+	cell.Row = min(cell.Row, g.Cells-1)
+
+	return cell, true
+}
+
+// Reference returns the grid reference of a world position, such as "G14",
+// or an empty string if the position lies outside the map
+func (g Grid) Reference(x, y float32) string {
+	cell, ok := g.Cell(x, y)
+	if !ok {
+		return ""
+	}
+	return cell.String()
+}
+
+// Bounds returns the world coordinates covered by a cell
+func (g Grid) Bounds(cell GridCell) Bounds {
+	top := float32(g.MapSize) - float32(cell.Row)*g.CellSize
+
+	return Bounds{
+		MinX: float32(cell.Column) * g.CellSize,
+		MinY: top - g.CellSize,
+		MaxX: float32(cell.Column+1) * g.CellSize,
+		MaxY: top,
+	}
+}
+
+// Parse parses a grid reference such as "G14" or "aa3" into a cell of the grid
+func (g Grid) Parse(ref string) (GridCell, error) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+
+	split := strings.IndexFunc(ref, func(r rune) bool { return r < 'A' || r > 'Z' })
+	if split <= 0 {
+		return GridCell{}, fmt.Errorf("%w: %q", ErrInvalidGridReference, ref)
+	}
+
+	row, err := strconv.Atoi(ref[split:])
+	if err != nil {
+		return GridCell{}, fmt.Errorf("%w: %q", ErrInvalidGridReference, ref)
+	}
+
+	column := 0
+	for _, r := range ref[:split] {
+		column = column*26 + int(r-'A') + 1
+		// Stop before a long prefix can overflow
+		if column > g.Cells {
+			break
+		}
+	}
+	column--
+
+	if column < 0 || column >= g.Cells || row < 0 || row >= g.Cells {
+		return GridCell{}, fmt.Errorf("%w: %q is outside the map", ErrInvalidGridReference, ref)
+	}
+
+	return GridCell{Column: column, Row: row}, nil
+}
+
+// GridColumn returns the letters of a grid column: A-Z, then AA, AB and so on
+func GridColumn(column int) string {
+	label := ""
+	for column >= 0 {
+		label = string(rune('A'+column%26)) + label
+		column = column/26 - 1
+	}
+	return label
+}
+
+// Distance returns the distance in metres between two world positions
+func Distance(x1, y1, x2, y2 float32) float32 {
+	return float32(math.Hypot(float64(x2-x1), float64(y2-y1)))
+}
+
+// compassPoints are the directions returned by Direction, clockwise from north
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// Direction returns the compass direction, such as "NE", from one world
+// position towards another. North is towards the top of the map.
+func Direction(fromX, fromY, toX, toY float32) string {
+	// Bearing in degrees clockwise from north
+	bearing := math.Atan2(float64(toX-fromX), float64(toY-fromY)) * 180 / math.Pi
+	if bearing < 0 {
+		bearing += 360
+	}
+
+	return compassPoints[int(math.Round(bearing/45))%len(compassPoints)]
+}
+
+// NearestMonument returns the monument closest to a world position and its
+// distance, or nil if there are no monuments. Train tunnel entrances are skipped.
+func NearestMonument(monuments []*proto.AppMap_Monument, x, y float32) (*proto.AppMap_Monument, float32) {
+	var nearest *proto.AppMap_Monument
+	var nearestDistance float32
+
+	for _, monument := range monuments {
+		if IsTrainTunnel(monument.GetToken()) {
+			continue
+		}
+
+		distance := Distance(x, y, monument.GetX(), monument.GetY())
+		if nearest == nil || distance < nearestDistance {
+			nearest, nearestDistance = monument, distance
+		}
+	}
+
+	return nearest, nearestDistance
+}
+
+// Describe describes a world position for chat, such as
+// "G14, 120m NE of Airfield". Positions outside the map are described by
+// their nearest monument only.
+func (g Grid) Describe(monuments []*proto.AppMap_Monument, x, y float32) string {
+	var parts []string
+	if ref := g.Reference(x, y); ref != "" {
+		parts = append(parts, ref)
+	}
+
+	if monument, distance := NearestMonument(monuments, x, y); monument != nil {
+		name := MonumentName(monument.GetToken())
+		if distance < g.CellSize/2 {
+			parts = append(parts, "at "+name)
+		} else {
+			direction := Direction(monument.GetX(), monument.GetY(), x, y)
+			parts = append(parts, fmt.Sprintf("%.0fm %s of %s", distance, direction, name))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "outside the map"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,86 @@
+package rustplus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// TestGrid_Reference tests converting between positions and grid references
+func TestGrid_Reference(t *testing.T) {
+	grid := NewGrid(4000)
+	if grid.Cells != 27 {
+		t.Fatalf("Expected 27 cells, got %d", grid.Cells)
+	}
+
+	tests := []struct {
+		x, y float32
+		want string
+	}{
+		{0, 3999, "A0"},
+		{1000, 2000, "G13"},
+		{3999, 0, "AA26"},
+		{-10, 2000, ""},
+		{2000, 4000, ""},
+	}
+
+	for _, tt := range tests {
+		if got := grid.Reference(tt.x, tt.y); got != tt.want {
+			t.Errorf("Reference(%v, %v) = %q, want %q", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	cell, err := grid.Parse("g13")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !grid.Bounds(cell).Contains(1000, 2000) {
+		t.Errorf("Expected bounds of %s to contain the position", cell)
+	}
+
+	for _, ref := range []string{"", "14", "G", "AB1", "A27", strings.Repeat("Z", 20) + "1"} {
+		if _, err := grid.Parse(ref); !errors.Is(err, ErrInvalidGridReference) {
+			t.Errorf("Parse(%q): expected ErrInvalidGridReference, got %v", ref, err)
+		}
+	}
+}
+
+// TestDirection tests compass directions between positions
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		x, y float32
+		want string
+	}{
+		{0, 100, "N"},
+		{100, 100, "NE"},
+		{100, 0, "E"},
+		{-100, -90, "SW"},
+		{-100, 10, "W"},
+	}
+
+	for _, tt := range tests {
+		if got := Direction(0, 0, tt.x, tt.y); got != tt.want {
+			t.Errorf("Direction to (%v, %v) = %s, want %s", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+// TestGrid_Describe tests describing a position relative to the nearest monument
+func TestGrid_Describe(t *testing.T) {
+	grid := NewGrid(4000)
+	monuments := []*proto.AppMap_Monument{
+		{Token: protobuf.String("airfield_display_name"), X: protobuf.Float32(1000), Y: protobuf.Float32(1000)},
+		{Token: protobuf.String("train_tunnel_display_name"), X: protobuf.Float32(2000), Y: protobuf.Float32(2000)},
+		{Token: protobuf.String("launchsite"), X: protobuf.Float32(3000), Y: protobuf.Float32(3000)},
+	}
+
+	if got := grid.Describe(monuments, 2000, 2100); got != "N12, 1345m SW of Launch Site" {
+		t.Errorf("Unexpected description %q", got)
+	}
+	if got := grid.Describe(monuments, 3010, 3000); got != "U6, at Launch Site" {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
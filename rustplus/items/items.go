@@ -0,0 +1,262 @@
+// Package items maps the numeric item IDs used by Rust+ to item names,
+// stack sizes and categories.
+//
+// The embedded catalogue is partial: it holds about a hundred common items,
+// a small part of the game's item list, so lookups of other items miss and
+// Name and Format fall back to the numeric ID. For complete names, export the
+// item definitions from the game as a JSON array of items and install them
+// with LoadFile and SetDefault.
+package items
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// embeddedItems is the catalogue built into the library. It only covers
+// common resources, components, weapons and deployables.
+//
+//go:embed items.json
+var embeddedItems []byte
+
+// Category is the crafting menu category of an item
+type Category string
+
+// Item categories
+const (
+	CategoryResources    Category = "Resources"
+	CategoryComponent    Category = "Component"
+	CategoryWeapon       Category = "Weapon"
+	CategoryAmmunition   Category = "Ammunition"
+	CategoryAttire       Category = "Attire"
+	CategoryMedical      Category = "Medical"
+	CategoryTool         Category = "Tool"
+	CategoryConstruction Category = "Construction"
+	CategoryItems        Category = "Items"
+	CategoryElectrical   Category = "Electrical"
+	CategoryFood         Category = "Food"
+	CategoryTraps        Category = "Traps"
+	CategoryFun          Category = "Fun"
+	CategoryMisc         Category = "Misc"
+)
+
+// Item describes a Rust item
+type Item struct {
+	ID        int32    `json:"id"`
+	ShortName string   `json:"shortname"`
+	Name      string   `json:"name"`
+	StackSize int      `json:"stackSize"`
+	Category  Category `json:"category"`
+}
+
+// Catalogue is a set of items indexed by ID and shortname
+type Catalogue struct {
+	byID        map[int32]Item
+	byShortName map[string]Item
+	mutex       sync.RWMutex
+}
+
+// NewCatalogue creates a catalogue of the given items
+func NewCatalogue(items []Item) *Catalogue {
+	c := &Catalogue{
+		byID:        make(map[int32]Item),
+		byShortName: make(map[string]Item),
+	}
+	c.Merge(items)
+	return c
+}
+
+// Parse parses a JSON array of items into a catalogue
+func Parse(data []byte) (*Catalogue, error) {
+	var items []Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse item catalogue: %w", err)
+	}
+	return NewCatalogue(items), nil
+}
+
+// Read reads a JSON array of items into a catalogue
+func Read(r io.Reader) (*Catalogue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read item catalogue: %w", err)
+	}
+	return Parse(data)
+}
+
+// LoadFile reads a JSON item catalogue from a file
+func LoadFile(path string) (*Catalogue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read item catalogue: %w", err)
+	}
+	return Parse(data)
+}
+
+// Merge adds items to the catalogue, replacing items with the same ID
+func (c *Catalogue) Merge(items []Item) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, item := range items {
+		if old, ok := c.byID[item.ID]; ok {
+			delete(c.byShortName, old.ShortName)
+		}
+		c.byID[item.ID] = item
+		if item.ShortName != "" {
+			c.byShortName[item.ShortName] = item
+		}
+	}
+}
+
+// Lookup returns the item with the given ID
+func (c *Catalogue) Lookup(id int32) (Item, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	item, ok := c.byID[id]
+	return item, ok
+}
+
+// ByShortName returns the item with the given shortname, such as "sulfur.ore"
+func (c *Catalogue) ByShortName(shortName string) (Item, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	item, ok := c.byShortName[shortName]
+	return item, ok
+}
+
+// Name returns the display name of an item, or its ID if it is unknown
+func (c *Catalogue) Name(id int32) string {
+	if item, ok := c.Lookup(id); ok {
+		return item.Name
+	}
+	return strconv.Itoa(int(id))
+}
+
+// Format describes a stack of items, such as "Sulfur Ore x 20000"
+func (c *Catalogue) Format(id int32, quantity int32) string {
+	return fmt.Sprintf("%s x %d", c.Name(id), quantity)
+}
+
+// Search returns the items whose name or shortname contains the query,
+// ignoring case, sorted by name
+func (c *Catalogue) Search(query string) []Item {
+	query = strings.ToLower(query)
+
+	c.mutex.RLock()
+	var items []Item
+	for _, item := range c.byID {
+		if strings.Contains(strings.ToLower(item.Name), query) || strings.Contains(item.ShortName, query) {
+			items = append(items, item)
+		}
+	}
+	c.mutex.RUnlock()
+
+	sortByName(items)
+	return items
+}
+
+// InCategory returns the items of a category sorted by name
+func (c *Catalogue) InCategory(category Category) []Item {
+	c.mutex.RLock()
+	var items []Item
+	for _, item := range c.byID {
+		if item.Category == category {
+			items = append(items, item)
+		}
+	}
+	c.mutex.RUnlock()
+
+	sortByName(items)
+	return items
+}
+
+// All returns every item in the catalogue sorted by name
+func (c *Catalogue) All() []Item {
+	c.mutex.RLock()
+	items := make([]Item, 0, len(c.byID))
+	for _, item := range c.byID {
+		items = append(items, item)
+	}
+	c.mutex.RUnlock()
+
+	sortByName(items)
+	return items
+}
+
+// Len returns the number of items in the catalogue
+func (c *Catalogue) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.byID)
+}
+
+// sortByName sorts items by display name, then ID
+func sortByName(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].ID < items[j].ID
+	})
+}
+
+var (
+	defaultCatalogue      *Catalogue
+	defaultCatalogueMutex sync.RWMutex
+)
+
+func init() {
+	c, err := Parse(embeddedItems)
+	if err != nil {
+		panic(err)
+	}
+	defaultCatalogue = c
+}
+
+// Default returns the catalogue used by the package-level helpers. It starts
+// as the embedded catalogue.
+func Default() *Catalogue {
+	defaultCatalogueMutex.RLock()
+	defer defaultCatalogueMutex.RUnlock()
+	return defaultCatalogue
+}
+
+// SetDefault replaces the catalogue used by the package-level helpers
+func SetDefault(c *Catalogue) {
+	defaultCatalogueMutex.Lock()
+	defaultCatalogue = c
+	defaultCatalogueMutex.Unlock()
+}
+
+// Lookup returns the item with the given ID from the default catalogue. With
+// the embedded catalogue, expect misses for less common items.
+func Lookup(id int32) (Item, bool) {
+	return Default().Lookup(id)
+}
+
+// ByShortName returns the item with the given shortname from the default catalogue
+func ByShortName(shortName string) (Item, bool) {
+	return Default().ByShortName(shortName)
+}
+
+// Name returns the display name of an item from the default catalogue, or
+// its ID if it is unknown
+func Name(id int32) string {
+	return Default().Name(id)
+}
+
+// Format describes a stack of items using the default catalogue, such as
+// "Sulfur Ore x 20000"
+func Format(id int32, quantity int32) string {
+	return Default().Format(id, quantity)
+}
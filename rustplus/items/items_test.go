@@ -0,0 +1,47 @@
+package items
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDefault tests lookups in the embedded catalogue
+func TestDefault(t *testing.T) {
+	if got := Format(-1157596551, 20000); got != "Sulfur Ore x 20000" {
+		t.Errorf("Unexpected format %q", got)
+	}
+	if got := Name(12345); got != "12345" {
+		t.Errorf("Expected unknown items to be named by ID, got %q", got)
+	}
+
+	item, ok := ByShortName("scrap")
+	if !ok || item.ID != -932201673 {
+		t.Errorf("Unexpected scrap item %+v", item)
+	}
+
+	for _, item := range Default().All() {
+		if item.Name == "" || item.ShortName == "" || item.StackSize <= 0 || item.Category == "" {
+			t.Errorf("Incomplete item %+v", item)
+		}
+	}
+}
+
+// TestRead tests loading and merging a catalogue at runtime
+func TestRead(t *testing.T) {
+	c, err := Read(strings.NewReader(`[{"id": 1, "shortname": "test.item", "name": "Test Item", "stackSize": 10, "category": "Misc"}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c.Merge([]Item{{ID: 1, ShortName: "test.renamed", Name: "Renamed", StackSize: 5, Category: CategoryMisc}})
+	if _, ok := c.ByShortName("test.item"); ok {
+		t.Error("Expected the replaced shortname to be removed")
+	}
+	if got := c.Name(1); got != "Renamed" {
+		t.Errorf("Expected Renamed, got %q", got)
+	}
+
+	if _, err := Parse([]byte("{")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
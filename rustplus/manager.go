@@ -0,0 +1,249 @@
+package rustplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrServerNotManaged is returned for a server the manager does not hold
+var ErrServerNotManaged = errors.New("server not managed")
+
+// ServerConfig holds what is needed to connect to a paired server
+type ServerConfig struct {
+	Server            string
+	Port              int
+	PlayerID          uint64
+	PlayerToken       int
+	UseFacepunchProxy bool
+}
+
+// Key returns the key the manager stores the server under, "host:port"
+func (s ServerConfig) Key() string {
+	return ServerKey(s.Server, s.Port)
+}
+
+// ServerKey returns the key of a server, "host:port"
+func ServerKey(server string, port int) string {
+	return net.JoinHostPort(server, strconv.Itoa(port))
+}
+
+// ServerEvent is a client event tagged with the server it came from
+type ServerEvent struct {
+	// Server is the key of the server, "host:port"
+	Server string
+	Event
+}
+
+// Manager holds a pool of clients for many servers, keeping each connected
+// and fanning their events into one channel
+type Manager struct {
+	// ConnectPolicy controls retrying the first connection to a server.
+	// Reconnecting after that is left to each client's reconnect policy.
+	ConnectPolicy ReconnectPolicy
+
+	opts      []ClientOption
+	servers   map[string]*managedServer
+	eventChan chan ServerEvent
+	mutex     sync.RWMutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// managedServer is a client in the manager's pool
+type managedServer struct {
+	config ServerConfig
+	client *Client
+	cancel context.CancelFunc
+}
+
+// NewManager creates a manager. The options are applied to every client;
+// unless overridden, clients reconnect forever.
+func NewManager(opts ...ClientOption) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	forever := DefaultReconnectPolicy
+	forever.MaxAttempts = ReconnectForever
+
+	return &Manager{
+		ConnectPolicy: forever,
+		opts:          append([]ClientOption{WithReconnectPolicy(forever)}, opts...),
+		servers:       make(map[string]*managedServer),
+		eventChan:     make(chan ServerEvent, 100),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Events returns a channel of events from every client
+func (m *Manager) Events() <-chan ServerEvent {
+	return m.eventChan
+}
+
+// Add adds a server and starts connecting to it in the background. Adding a
+// server that is already managed with the same credentials returns the
+// existing client; new credentials, such as after pairing again, replace it.
+func (m *Manager) Add(config ServerConfig) (*Client, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.ctx.Err() != nil {
+		return nil, ErrClientClosed
+	}
+
+	key := config.Key()
+	if existing, ok := m.servers[key]; ok {
+		if existing.config == config {
+			return existing.client, nil
+		}
+		m.stop(existing)
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	server := &managedServer{
+		config: config,
+		client: NewClient(config.Server, config.Port, config.PlayerID, config.PlayerToken, config.UseFacepunchProxy, m.opts...),
+		cancel: cancel,
+	}
+	m.servers[key] = server
+
+	m.wg.Add(2)
+	go m.forward(key, server.client)
+	go m.connect(ctx, key, server.client)
+
+	return server.client, nil
+}
+
+// Remove disconnects from a server and removes it from the pool
+func (m *Manager) Remove(key string) error {
+	m.mutex.Lock()
+	server, ok := m.servers[key]
+	if ok {
+		delete(m.servers, key)
+	}
+	m.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrServerNotManaged, key)
+	}
+	return m.stop(server)
+}
+
+// Client returns the client for a server
+func (m *Manager) Client(key string) (*Client, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	server, ok := m.servers[key]
+	if !ok {
+		return nil, false
+	}
+	return server.client, true
+}
+
+// Servers returns the keys of the managed servers, sorted
+func (m *Manager) Servers() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	keys := make([]string, 0, len(m.servers))
+	for key := range m.servers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Config returns the configuration of a server
+func (m *Manager) Config(key string) (ServerConfig, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	server, ok := m.servers[key]
+	if !ok {
+		return ServerConfig{}, false
+	}
+	return server.config, true
+}
+
+// HandlePairing adds the server from a pairing notification. Pairing again
+// with new credentials replaces the server's client.
+func (m *Manager) HandlePairing(notification *PairingNotification) (*Client, error) {
+	return m.Add(notification.ServerConfig)
+}
+
+// Close disconnects every client and closes the event channel
+func (m *Manager) Close() error {
+	m.mutex.Lock()
+	m.cancel()
+	servers := m.servers
+	m.servers = make(map[string]*managedServer)
+	m.mutex.Unlock()
+
+	var errs []error
+	for _, server := range servers {
+		errs = append(errs, m.stop(server))
+	}
+
+	// Every forwarder has finished once the clients are closed
+	m.wg.Wait()
+	close(m.eventChan)
+
+	return errors.Join(errs...)
+}
+
+// stop cancels connecting to a server and closes its client
+func (m *Manager) stop(server *managedServer) error {
+	server.cancel()
+	return server.client.Close()
+}
+
+// connect connects a client, retrying with the connect policy
+func (m *Manager) connect(ctx context.Context, key string, client *Client) {
+	defer m.wg.Done()
+
+	for attempt := 1; ; attempt++ {
+		err := client.ConnectContext(ctx)
+		if err == nil || errors.Is(err, ErrClientClosed) || ctx.Err() != nil {
+			return
+		}
+
+		m.emit(ServerEvent{Server: key, Event: Event{Type: EventError, Error: fmt.Errorf("failed to connect: %w", err)}})
+
+		if !m.ConnectPolicy.allows(attempt) {
+			return
+		}
+
+		select {
+		case <-time.After(m.ConnectPolicy.Delay(attempt)):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// forward tags a client's events with its server until the client is closed
+func (m *Manager) forward(key string, client *Client) {
+	defer m.wg.Done()
+
+	for event := range client.Events() {
+		m.emit(ServerEvent{Server: key, Event: event})
+	}
+}
+
+// emit sends an event to the fan-in channel
+func (m *Manager) emit(event ServerEvent) {
+	select {
+	case m.eventChan <- event:
+		// Event sent successfully
+	default:
+		// Channel is full, log the error
+		fmt.Printf("Warning: Manager event channel is full, event %s from %s dropped\n", event.Type, event.Server)
+	}
+}
@@ -0,0 +1,186 @@
+package mapview
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// Style sets the colours and sizes used to draw overlays
+type Style struct {
+	Grid       color.NRGBA
+	GridLabel  color.NRGBA
+	Monument   color.NRGBA
+	Label      color.NRGBA
+	LabelShade color.NRGBA
+	// TextScale is the number of pixels per font dot
+	TextScale int
+	// MarkerRadius is the radius in pixels of player and team member dots
+	MarkerRadius int
+
+	Player         color.NRGBA
+	VendingMachine color.NRGBA
+	OutOfStock     color.NRGBA
+	Event          color.NRGBA
+	MemberOnline   color.NRGBA
+	MemberOffline  color.NRGBA
+	MemberDead     color.NRGBA
+	Note           color.NRGBA
+	DeathNote      color.NRGBA
+	// ShowTrainTunnel draws train tunnel entrances with the other monuments
+	ShowTrainTunnel bool
+}
+
+// DefaultStyle is the style canvases start with
+var DefaultStyle = Style{
+	Grid:         color.NRGBA{R: 0, G: 0, B: 0, A: 80},
+	GridLabel:    color.NRGBA{R: 0, G: 0, B: 0, A: 200},
+	Monument:     color.NRGBA{R: 40, G: 40, B: 40, A: 255},
+	Label:        color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+	LabelShade:   color.NRGBA{R: 0, G: 0, B: 0, A: 160},
+	TextScale:    2,
+	MarkerRadius: 6,
+
+	Player:         color.NRGBA{R: 60, G: 200, B: 255, A: 255},
+	VendingMachine: color.NRGBA{R: 80, G: 200, B: 80, A: 255},
+	OutOfStock:     color.NRGBA{R: 200, G: 60, B: 60, A: 255},
+	Event:          color.NRGBA{R: 255, G: 170, B: 0, A: 255},
+	MemberOnline:   color.NRGBA{R: 120, G: 220, B: 70, A: 255},
+	MemberOffline:  color.NRGBA{R: 150, G: 150, B: 150, A: 255},
+	MemberDead:     color.NRGBA{R: 220, G: 40, B: 40, A: 255},
+	Note:           color.NRGBA{R: 255, G: 230, B: 60, A: 255},
+	DeathNote:      color.NRGBA{R: 220, G: 40, B: 40, A: 255},
+}
+
+// Canvas is a copy of the map image that overlays are drawn on
+type Canvas struct {
+	Map   *Map
+	Style Style
+	img   *image.NRGBA
+}
+
+// Image returns the image drawn so far
+func (c *Canvas) Image() *image.NRGBA {
+	return c.img
+}
+
+// DrawGrid draws the in-game grid with each cell's reference in its top-left corner
+func (c *Canvas) DrawGrid() {
+	grid := c.Map.Grid()
+
+	for column := 0; column < grid.Cells; column++ {
+		for row := 0; row < grid.Cells; row++ {
+			cell := rustplus.GridCell{Column: column, Row: row}
+			bounds := grid.Bounds(cell)
+			topLeft := c.Map.WorldToPixel(bounds.MinX, bounds.MaxY)
+			bottomRight := c.Map.WorldToPixel(bounds.MaxX, bounds.MinY)
+
+			c.line(topLeft, image.Pt(bottomRight.X, topLeft.Y), c.Style.Grid)
+			c.line(topLeft, image.Pt(topLeft.X, bottomRight.Y), c.Style.Grid)
+			c.text(topLeft.Add(image.Pt(2, 2)), cell.String(), c.Style.GridLabel, c.Style.TextScale/2+1)
+		}
+	}
+
+	// Close off the right and bottom edges
+	topLeft := c.Map.WorldToPixel(0, float32(grid.MapSize))
+	bottomRight := c.Map.WorldToPixel(float32(grid.MapSize), 0)
+	c.line(image.Pt(bottomRight.X, topLeft.Y), bottomRight, c.Style.Grid)
+	c.line(image.Pt(topLeft.X, bottomRight.Y), bottomRight, c.Style.Grid)
+}
+
+// DrawMonuments draws a dot and the name of every monument
+func (c *Canvas) DrawMonuments() {
+	for _, monument := range c.Map.Monuments {
+		if !c.Style.ShowTrainTunnel && rustplus.IsTrainTunnel(monument.GetToken()) {
+			continue
+		}
+
+		p := c.Map.WorldToPixel(monument.GetX(), monument.GetY())
+		c.circle(p, c.Style.MarkerRadius/2, c.Style.Monument)
+		c.label(p, rustplus.MonumentName(monument.GetToken()))
+	}
+}
+
+// DrawMarkers draws players, vending machines and world events
+func (c *Canvas) DrawMarkers(markers *rustplus.MapMarkers) {
+	for _, marker := range markers.Markers {
+		p := c.Map.WorldToPixel(marker.X, marker.Y)
+
+		switch {
+		case marker.Type == proto.AppMarkerType_GenericRadius:
+			c.ring(p, c.Style.MarkerRadius*2, marker.Color1)
+		case rustplus.IsEventMarker(marker.Type):
+			c.circle(p, c.Style.MarkerRadius, c.Style.Event)
+			c.label(p, rustplus.MarkerTypeName(marker.Type))
+		}
+	}
+
+	for _, vm := range markers.VendingMachines() {
+		p := c.Map.WorldToPixel(vm.X, vm.Y)
+		fill := c.Style.VendingMachine
+		if vm.OutOfStock {
+			fill = c.Style.OutOfStock
+		}
+		r := c.Style.MarkerRadius / 2
+		c.rect(image.Rect(p.X-r, p.Y-r, p.X+r+1, p.Y+r+1), fill)
+	}
+
+	for _, player := range markers.Players() {
+		p := c.Map.WorldToPixel(player.X, player.Y)
+		c.circle(p, c.Style.MarkerRadius, c.Style.Player)
+		c.label(p, player.Name)
+	}
+}
+
+// DrawTeam draws the position and name of every team member, coloured by
+// whether they are online, offline or dead
+func (c *Canvas) DrawTeam(team *proto.AppTeamInfo) {
+	for _, member := range team.GetMembers() {
+		fill := c.Style.MemberOnline
+		switch {
+		case !member.GetIsAlive():
+			fill = c.Style.MemberDead
+		case !member.GetIsOnline():
+			fill = c.Style.MemberOffline
+		}
+
+		p := c.Map.WorldToPixel(member.GetX(), member.GetY())
+		c.circle(p, c.Style.MarkerRadius, fill)
+		c.label(p, member.GetName())
+	}
+}
+
+// DrawNotes draws the team's map notes and death markers
+func (c *Canvas) DrawNotes(team *proto.AppTeamInfo) {
+	notes := append(append([]*proto.AppTeamInfo_Note(nil), team.GetMapNotes()...), team.GetLeaderMapNotes()...)
+
+	for _, note := range notes {
+		p := c.Map.WorldToPixel(note.GetX(), note.GetY())
+		r := c.Style.MarkerRadius
+
+		// Type 0 is the player's death marker, everything else is a placed note
+		if note.GetType() == 0 {
+			c.line(p.Add(image.Pt(-r, -r)), p.Add(image.Pt(r, r)), c.Style.DeathNote)
+			c.line(p.Add(image.Pt(-r, r)), p.Add(image.Pt(r, -r)), c.Style.DeathNote)
+			continue
+		}
+
+		c.ring(p, r, c.Style.Note)
+	}
+}
+
+// label draws text centred below a point
+func (c *Canvas) label(p image.Point, s string) {
+	if s == "" {
+		return
+	}
+
+	scale := c.Style.TextScale
+	width := textWidth(s, scale)
+	origin := image.Pt(p.X-width/2, p.Y+c.Style.MarkerRadius+2)
+
+	c.rect(image.Rect(origin.X-scale, origin.Y-scale, origin.X+width+scale, origin.Y+glyphHeight*scale+scale), c.Style.LabelShade)
+	c.text(origin, s, c.Style.Label, scale)
+}
@@ -0,0 +1,138 @@
+package mapview
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// blend draws a colour over a pixel using its alpha
+func (c *Canvas) blend(x, y int, col color.NRGBA) {
+	if !(image.Point{X: x, Y: y}).In(c.img.Bounds()) || col.A == 0 {
+		return
+	}
+
+	i := c.img.PixOffset(x, y)
+	pix := c.img.Pix[i : i+4 : i+4]
+	if col.A == 0xFF {
+		pix[0], pix[1], pix[2], pix[3] = col.R, col.G, col.B, 0xFF
+		return
+	}
+
+	a := uint32(col.A)
+	mix := func(dst, src uint8) uint8 {
+		return uint8((uint32(src)*a + uint32(dst)*(0xFF-a)) / 0xFF)
+	}
+	pix[0] = mix(pix[0], col.R)
+	pix[1] = mix(pix[1], col.G)
+	pix[2] = mix(pix[2], col.B)
+	pix[3] = 0xFF
+}
+
+// rect fills a rectangle
+func (c *Canvas) rect(r image.Rectangle, col color.NRGBA) {
+	r = r.Intersect(c.img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c.blend(x, y, col)
+		}
+	}
+}
+
+// circle fills a circle
+func (c *Canvas) circle(center image.Point, radius int, col color.NRGBA) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				c.blend(center.X+x, center.Y+y, col)
+			}
+		}
+	}
+}
+
+// ring draws the outline of a circle two pixels wide
+func (c *Canvas) ring(center image.Point, radius int, col color.NRGBA) {
+	inner := (radius - 2) * (radius - 2)
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if d := x*x + y*y; d <= radius*radius && d > inner {
+				c.blend(center.X+x, center.Y+y, col)
+			}
+		}
+	}
+}
+
+// line draws a one pixel wide line between two points
+func (c *Canvas) line(from, to image.Point, col color.NRGBA) {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+	e := dx + dy
+
+	for p := from; ; {
+		c.blend(p.X, p.Y, col)
+		if p == to {
+			return
+		}
+
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			p.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			p.Y += sy
+		}
+	}
+}
+
+// text draws a string with its top-left corner at origin
+func (c *Canvas) text(origin image.Point, s string, col color.NRGBA, scale int) {
+	for _, r := range strings.ToUpper(s) {
+		glyph, ok := glyphs[r]
+		if !ok {
+			glyph = glyphs['?']
+		}
+
+		for row, bits := range glyph {
+			for column := 0; column < glyphWidth; column++ {
+				if bits&(1<<(glyphWidth-1-column)) == 0 {
+					continue
+				}
+				x, y := origin.X+column*scale, origin.Y+row*scale
+				c.rect(image.Rect(x, y, x+scale, y+scale), col)
+			}
+		}
+
+		origin.X += (glyphWidth + 1) * scale
+	}
+}
+
+// textWidth returns the width in pixels of a string drawn with text
+func textWidth(s string, scale int) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+1) - 1) * scale
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// sign returns -1, 0 or 1 depending on the sign of n
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
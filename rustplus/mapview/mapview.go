@@ -0,0 +1,153 @@
+// Package mapview renders the Rust+ map with monuments, markers, team
+// members, map notes and a grid drawn on top of it.
+package mapview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// ErrInvalidMapSize is returned when the world size of the map is not known
+var ErrInvalidMapSize = errors.New("invalid map size")
+
+// Map is a decoded map image together with what is needed to place world
+// coordinates on it
+type Map struct {
+	Image *image.NRGBA
+	// Size is the world size of the map in metres, from AppInfo.MapSize
+	Size uint32
+	// OceanMargin is the number of pixels of ocean around the island
+	OceanMargin int
+	Monuments   []*proto.AppMap_Monument
+	// Background is the ocean colour sent with the map
+	Background color.NRGBA
+}
+
+// New decodes the map image returned by GetMap. mapSize is the world size
+// reported by GetInfo.
+func New(data *proto.AppMap, mapSize uint32) (*Map, error) {
+	if mapSize == 0 {
+		return nil, ErrInvalidMapSize
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data.GetJpgImage()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode map image: %w", err)
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	background, err := ParseColor(data.GetBackground())
+	if err != nil {
+		background = color.NRGBA{R: 0x12, G: 0x40, B: 0x4D, A: 0xFF}
+	}
+
+	return &Map{
+		Image:       nrgba,
+		Size:        mapSize,
+		OceanMargin: int(data.GetOceanMargin()),
+		Monuments:   data.GetMonuments(),
+		Background:  background,
+	}, nil
+}
+
+// scale returns the number of pixels per world metre
+func (m *Map) scale() float64 {
+	return float64(m.Image.Bounds().Dx()-2*m.OceanMargin) / float64(m.Size)
+}
+
+// WorldToPixel converts world coordinates into a pixel position. The world
+// origin is the bottom-left corner of the island, inside the ocean margin.
+func (m *Map) WorldToPixel(x, y float32) image.Point {
+	scale := m.scale()
+	height := m.Image.Bounds().Dy()
+
+	return image.Point{
+		X: m.OceanMargin + int(float64(x)*scale+0.5),
+		Y: height - m.OceanMargin - int(float64(y)*scale+0.5),
+	}
+}
+
+// PixelToWorld converts a pixel position into world coordinates
+func (m *Map) PixelToWorld(p image.Point) (x, y float32) {
+	scale := m.scale()
+	height := m.Image.Bounds().Dy()
+
+	x = float32(float64(p.X-m.OceanMargin) / scale)
+	y = float32(float64(height-m.OceanMargin-p.Y) / scale)
+	return x, y
+}
+
+// Grid returns the in-game grid of the map
+func (m *Map) Grid() rustplus.Grid {
+	return rustplus.NewGrid(m.Size)
+}
+
+// Canvas returns a canvas to draw overlays on a copy of the map image
+func (m *Map) Canvas() *Canvas {
+	img := image.NewNRGBA(m.Image.Bounds())
+	copy(img.Pix, m.Image.Pix)
+
+	return &Canvas{
+		Map:   m,
+		Style: DefaultStyle,
+		img:   img,
+	}
+}
+
+// ParseColor parses a colour in the "#RRGGBB" or "#RRGGBBAA" form used by AppMap.Background
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %q", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %q: %w", s, err)
+	}
+
+	return color.NRGBA{
+		R: uint8(value >> 24),
+		G: uint8(value >> 16),
+		B: uint8(value >> 8),
+		A: uint8(value),
+	}, nil
+}
+
+// WritePNG encodes the canvas as a PNG image
+func (c *Canvas) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+// SavePNG writes the canvas to a PNG file
+func (c *Canvas) SavePNG(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if err := c.WritePNG(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,104 @@
+package mapview
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// testMap creates a map of the given pixel size with a uniform colour
+func testMap(t *testing.T, size, margin int, mapSize uint32) *Map {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	m, err := New(&proto.AppMap{
+		Width:       protobuf.Uint32(uint32(size)),
+		Height:      protobuf.Uint32(uint32(size)),
+		JpgImage:    buf.Bytes(),
+		OceanMargin: protobuf.Int32(int32(margin)),
+		Background:  protobuf.String("#12404D"),
+	}, mapSize)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return m
+}
+
+// TestMap_WorldToPixel tests converting between world and pixel coordinates
+func TestMap_WorldToPixel(t *testing.T) {
+	m := testMap(t, 1000, 100, 4000)
+
+	tests := []struct {
+		x, y float32
+		want image.Point
+	}{
+		{0, 0, image.Pt(100, 900)},
+		{4000, 4000, image.Pt(900, 100)},
+		{2000, 1000, image.Pt(500, 700)},
+	}
+
+	for _, tt := range tests {
+		got := m.WorldToPixel(tt.x, tt.y)
+		if got != tt.want {
+			t.Errorf("WorldToPixel(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+
+		x, y := m.PixelToWorld(got)
+		if x != tt.x || y != tt.y {
+			t.Errorf("PixelToWorld(%v) = (%v, %v), want (%v, %v)", got, x, y, tt.x, tt.y)
+		}
+	}
+
+	if m.Background != (color.NRGBA{R: 0x12, G: 0x40, B: 0x4D, A: 0xFF}) {
+		t.Errorf("Unexpected background %v", m.Background)
+	}
+}
+
+// TestCanvas_WritePNG tests drawing overlays and exporting the result
+func TestCanvas_WritePNG(t *testing.T) {
+	m := testMap(t, 500, 50, 3000)
+	m.Monuments = []*proto.AppMap_Monument{
+		{Token: protobuf.String("airfield_display_name"), X: protobuf.Float32(1500), Y: protobuf.Float32(1500)},
+	}
+
+	canvas := m.Canvas()
+	canvas.DrawGrid()
+	canvas.DrawMonuments()
+	canvas.DrawTeam(&proto.AppTeamInfo{
+		Members: []*proto.AppTeamInfo_Member{
+			{Name: protobuf.String("Alice"), X: protobuf.Float32(500), Y: protobuf.Float32(500), IsOnline: protobuf.Bool(true), IsAlive: protobuf.Bool(true)},
+		},
+	})
+
+	// The member dot is drawn over the original image only
+	p := m.WorldToPixel(500, 500)
+	if got := canvas.Image().NRGBAAt(p.X, p.Y); got != DefaultStyle.MemberOnline {
+		t.Errorf("Expected member colour at %v, got %v", p, got)
+	}
+	if m.Image.NRGBAAt(p.X, p.Y) == DefaultStyle.MemberOnline {
+		t.Error("Drawing modified the map image")
+	}
+
+	var buf bytes.Buffer
+	if err := canvas.WritePNG(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+}
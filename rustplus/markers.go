@@ -0,0 +1,341 @@
+package rustplus
+
+import (
+	"context"
+	"image/color"
+	"strconv"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/items"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// Marker represents a marker shown on the in-game map
+type Marker struct {
+	ID       uint32
+	Type     proto.AppMarkerType
+	X        float32
+	Y        float32
+	Rotation float32
+	Radius   float32
+	Alpha    float32
+	Color1   color.NRGBA
+	Color2   color.NRGBA
+}
+
+// PlayerMarker represents the position of a player on the map
+type PlayerMarker struct {
+	Marker
+	SteamID uint64
+	Name    string
+}
+
+// VendingMachine represents a vending machine shop on the map
+type VendingMachine struct {
+	Marker
+	Name       string
+	OutOfStock bool
+	SellOrders []SellOrder
+}
+
+// CargoShip represents the cargo ship on the map. Rotation is its heading.
+type CargoShip struct {
+	Marker
+}
+
+// PatrolHelicopter represents the patrol helicopter on the map. Rotation is
+// its heading.
+type PatrolHelicopter struct {
+	Marker
+}
+
+// CH47 represents a Chinook on the map. Rotation is its heading.
+type CH47 struct {
+	Marker
+}
+
+// Explosion represents a crash site or other explosion on the map
+type Explosion struct {
+	Marker
+}
+
+// LockedCrate represents a locked crate on the map
+type LockedCrate struct {
+	Marker
+}
+
+// RadiusMarker represents a circle drawn on the map, described by Radius,
+// Color1, Color2 and Alpha
+type RadiusMarker struct {
+	Marker
+}
+
+// SellOrder represents an item sold by a vending machine
+type SellOrder struct {
+	ItemID              int32
+	Quantity            int32
+	CurrencyID          int32
+	CostPerItem         int32
+	AmountInStock       int32
+	ItemIsBlueprint     bool
+	CurrencyIsBlueprint bool
+	ItemCondition       float32
+	ItemConditionMax    float32
+	Discount            float32
+	PriceMultiplier     float32
+}
+
+// String describes the order using the default item catalogue, such as
+// "Sulfur Ore x 1000 for Scrap x 50"
+func (o SellOrder) String() string {
+	return formatItem(o.ItemID, o.Quantity, o.ItemIsBlueprint) + " for " + formatItem(o.CurrencyID, o.CostPerItem, o.CurrencyIsBlueprint)
+}
+
+// formatItem describes a stack of items using the default item catalogue
+func formatItem(itemID, quantity int32, isBlueprint bool) string {
+	if isBlueprint {
+		return items.Name(itemID) + " Blueprint x " + strconv.Itoa(int(quantity))
+	}
+	return items.Format(itemID, quantity)
+}
+
+// InStock reports whether the sell order can currently be bought
+func (o SellOrder) InStock() bool {
+	return o.AmountInStock > 0
+}
+
+// MapMarkers holds the markers returned by GetMapMarkers
+type MapMarkers struct {
+	Markers         []Marker
+	players         []PlayerMarker
+	vendingMachines []VendingMachine
+}
+
+// NewMapMarkers converts the raw protocol buffer markers into typed markers
+func NewMapMarkers(markers *proto.AppMapMarkers) *MapMarkers {
+	result := &MapMarkers{}
+
+	for _, m := range markers.GetMarkers() {
+		marker := newMarker(m)
+		result.Markers = append(result.Markers, marker)
+
+		switch m.GetType() {
+		case proto.AppMarkerType_Player:
+			result.players = append(result.players, PlayerMarker{
+				Marker:  marker,
+				SteamID: m.GetSteamId(),
+				Name:    m.GetName(),
+			})
+		case proto.AppMarkerType_VendingMachine:
+			vendingMachine := VendingMachine{
+				Marker:     marker,
+				Name:       m.GetName(),
+				OutOfStock: m.GetOutOfStock(),
+			}
+			for _, order := range m.GetSellOrders() {
+				vendingMachine.SellOrders = append(vendingMachine.SellOrders, newSellOrder(order))
+			}
+			result.vendingMachines = append(result.vendingMachines, vendingMachine)
+		}
+	}
+
+	return result
+}
+
+// Players returns the player markers
+func (m *MapMarkers) Players() []PlayerMarker {
+	return m.players
+}
+
+// VendingMachines returns the vending machine markers
+func (m *MapMarkers) VendingMachines() []VendingMachine {
+	return m.vendingMachines
+}
+
+// CargoShips returns the cargo ship markers
+func (m *MapMarkers) CargoShips() []CargoShip {
+	return typedMarkers(m, proto.AppMarkerType_CargoShip, func(marker Marker) CargoShip {
+		return CargoShip{Marker: marker}
+	})
+}
+
+// PatrolHelicopters returns the patrol helicopter markers
+func (m *MapMarkers) PatrolHelicopters() []PatrolHelicopter {
+	return typedMarkers(m, proto.AppMarkerType_PatrolHelicopter, func(marker Marker) PatrolHelicopter {
+		return PatrolHelicopter{Marker: marker}
+	})
+}
+
+// CH47s returns the Chinook markers
+func (m *MapMarkers) CH47s() []CH47 {
+	return typedMarkers(m, proto.AppMarkerType_CH47, func(marker Marker) CH47 {
+		return CH47{Marker: marker}
+	})
+}
+
+// Explosions returns the explosion markers
+func (m *MapMarkers) Explosions() []Explosion {
+	return typedMarkers(m, proto.AppMarkerType_Explosion, func(marker Marker) Explosion {
+		return Explosion{Marker: marker}
+	})
+}
+
+// LockedCrates returns the locked crate markers
+func (m *MapMarkers) LockedCrates() []LockedCrate {
+	return typedMarkers(m, proto.AppMarkerType_Crate, func(marker Marker) LockedCrate {
+		return LockedCrate{Marker: marker}
+	})
+}
+
+// RadiusMarkers returns the generic radius markers
+func (m *MapMarkers) RadiusMarkers() []RadiusMarker {
+	return typedMarkers(m, proto.AppMarkerType_GenericRadius, func(marker Marker) RadiusMarker {
+		return RadiusMarker{Marker: marker}
+	})
+}
+
+// typedMarkers wraps the markers of the given type in their typed struct
+func typedMarkers[T any](m *MapMarkers, markerType proto.AppMarkerType, wrap func(Marker) T) []T {
+	var markers []T
+	for _, marker := range m.Markers {
+		if marker.Type == markerType {
+			markers = append(markers, wrap(marker))
+		}
+	}
+	return markers
+}
+
+// Events returns the markers of world events: explosions, CH47s, cargo
+// ships, locked crates and patrol helicopters
+func (m *MapMarkers) Events() []Marker {
+	var events []Marker
+	for _, marker := range m.Markers {
+		if IsEventMarker(marker.Type) {
+			events = append(events, marker)
+		}
+	}
+	return events
+}
+
+// ByType returns the markers of the given type
+func (m *MapMarkers) ByType(markerType proto.AppMarkerType) []Marker {
+	var markers []Marker
+	for _, marker := range m.Markers {
+		if marker.Type == markerType {
+			markers = append(markers, marker)
+		}
+	}
+	return markers
+}
+
+// MarkerTypeName returns a readable name for a marker type, such as "Cargo Ship"
+func MarkerTypeName(markerType proto.AppMarkerType) string {
+	switch markerType {
+	case proto.AppMarkerType_Player:
+		return "Player"
+	case proto.AppMarkerType_Explosion:
+		return "Explosion"
+	case proto.AppMarkerType_VendingMachine:
+		return "Vending Machine"
+	case proto.AppMarkerType_CH47:
+		return "Chinook"
+	case proto.AppMarkerType_CargoShip:
+		return "Cargo Ship"
+	case proto.AppMarkerType_Crate:
+		return "Locked Crate"
+	case proto.AppMarkerType_GenericRadius:
+		return "Radius"
+	case proto.AppMarkerType_PatrolHelicopter:
+		return "Patrol Helicopter"
+	default:
+		return markerType.String()
+	}
+}
+
+// IsEventMarker reports whether markers of the given type represent a world event
+func IsEventMarker(markerType proto.AppMarkerType) bool {
+	switch markerType {
+	case proto.AppMarkerType_Explosion,
+		proto.AppMarkerType_CH47,
+		proto.AppMarkerType_CargoShip,
+		proto.AppMarkerType_Crate,
+		proto.AppMarkerType_PatrolHelicopter:
+		return true
+	default:
+		return false
+	}
+}
+
+// newMarker converts the common fields of a protocol buffer marker
+func newMarker(m *proto.AppMarker) Marker {
+	return Marker{
+		ID:       m.GetId(),
+		Type:     m.GetType(),
+		X:        m.GetX(),
+		Y:        m.GetY(),
+		Rotation: m.GetRotation(),
+		Radius:   m.GetRadius(),
+		Alpha:    m.GetAlpha(),
+		Color1:   vector4ToColor(m.GetColor1()),
+		Color2:   vector4ToColor(m.GetColor2()),
+	}
+}
+
+// newSellOrder converts a protocol buffer sell order
+func newSellOrder(o *proto.AppMarker_SellOrder) SellOrder {
+	return SellOrder{
+		ItemID:              o.GetItemId(),
+		Quantity:            o.GetQuantity(),
+		CurrencyID:          o.GetCurrencyId(),
+		CostPerItem:         o.GetCostPerItem(),
+		AmountInStock:       o.GetAmountInStock(),
+		ItemIsBlueprint:     o.GetItemIsBlueprint(),
+		CurrencyIsBlueprint: o.GetCurrencyIsBlueprint(),
+		ItemCondition:       o.GetItemCondition(),
+		ItemConditionMax:    o.GetItemConditionMax(),
+		Discount:            o.GetDiscount(),
+		PriceMultiplier:     o.GetPriceMultiplier(),
+	}
+}
+
+// vector4ToColor converts a normalised RGBA vector into a color
+func vector4ToColor(v *proto.Vector4) color.NRGBA {
+	channel := func(f float32) uint8 {
+		if f <= 0 {
+			return 0
+		}
+		if f >= 1 {
+			return 255
+		}
+		return uint8(f*255 + 0.5)
+	}
+
+	return color.NRGBA{
+		R: channel(v.GetX()),
+		G: channel(v.GetY()),
+		B: channel(v.GetZ()),
+		A: channel(v.GetW()),
+	}
+}
+
+// GetMapMarkers gets the markers currently shown on the map
+func (c *Client) GetMapMarkers() (*MapMarkers, error) {
+	return c.GetMapMarkersContext(context.Background())
+}
+
+// GetMapMarkersContext gets the markers currently shown on the map using the provided context
+func (c *Client) GetMapMarkersContext(ctx context.Context) (*MapMarkers, error) {
+	request := &proto.AppRequest{
+		GetMapMarkers: &proto.AppEmpty{},
+	}
+
+	response, err := c.request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.MapMarkers == nil {
+		return nil, ErrInvalidResponse
+	}
+
+	return NewMapMarkers(response.GetMapMarkers()), nil
+}
@@ -0,0 +1,48 @@
+package rustplus
+
+import (
+	"testing"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// TestMapMarkers_Typed tests that markers are sorted into their typed structs
+func TestMapMarkers_Typed(t *testing.T) {
+	marker := func(id uint32, markerType proto.AppMarkerType) *proto.AppMarker {
+		return &proto.AppMarker{
+			Id:       protobuf.Uint32(id),
+			Type:     markerType.Enum(),
+			X:        protobuf.Float32(100),
+			Y:        protobuf.Float32(200),
+			Rotation: protobuf.Float32(90),
+		}
+	}
+
+	markers := NewMapMarkers(&proto.AppMapMarkers{Markers: []*proto.AppMarker{
+		marker(1, proto.AppMarkerType_CargoShip),
+		marker(2, proto.AppMarkerType_PatrolHelicopter),
+		marker(3, proto.AppMarkerType_CH47),
+		marker(4, proto.AppMarkerType_CH47),
+		marker(5, proto.AppMarkerType_Explosion),
+		marker(6, proto.AppMarkerType_Crate),
+		marker(7, proto.AppMarkerType_GenericRadius),
+		marker(8, proto.AppMarkerType_Player),
+	}})
+
+	if ships := markers.CargoShips(); len(ships) != 1 || ships[0].ID != 1 || ships[0].Rotation != 90 {
+		t.Errorf("Unexpected cargo ships %+v", ships)
+	}
+	if helis := markers.PatrolHelicopters(); len(helis) != 1 || helis[0].ID != 2 {
+		t.Errorf("Unexpected patrol helicopters %+v", helis)
+	}
+	if chinooks := markers.CH47s(); len(chinooks) != 2 || chinooks[1].ID != 4 {
+		t.Errorf("Unexpected CH47s %+v", chinooks)
+	}
+	if len(markers.Explosions()) != 1 || len(markers.LockedCrates()) != 1 || len(markers.RadiusMarkers()) != 1 {
+		t.Errorf("Expected one explosion, locked crate and radius marker")
+	}
+	if events := markers.Events(); len(events) != 6 {
+		t.Errorf("Expected 6 event markers, got %d", len(events))
+	}
+}
@@ -0,0 +1,133 @@
+package market
+
+import (
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+)
+
+// EventType is the type of a market event
+type EventType string
+
+const (
+	// EventShopOpened is emitted when a vending machine appears on the map
+	EventShopOpened EventType = "shop_opened"
+	// EventShopClosed is emitted when a vending machine disappears from the map
+	EventShopClosed EventType = "shop_closed"
+	// EventOrderAdded is emitted when a shop starts selling an item
+	EventOrderAdded EventType = "order_added"
+	// EventOrderRemoved is emitted when a shop stops selling an item
+	EventOrderRemoved EventType = "order_removed"
+	// EventOutOfStock is emitted when a sell order runs out of stock
+	EventOutOfStock EventType = "out_of_stock"
+	// EventRestocked is emitted when a sell order is back in stock
+	EventRestocked EventType = "restocked"
+	// EventPriceChanged is emitted when the quantity or cost of a sell order changes
+	EventPriceChanged EventType = "price_changed"
+)
+
+// Event is a change between two snapshots
+type Event struct {
+	Type EventType
+	Time time.Time
+	Shop Shop
+	// Order is the sell order the event is about, for order events
+	Order *rustplus.SellOrder
+	// Previous is the order before the change, for price and stock events
+	Previous *rustplus.SellOrder
+}
+
+// orderKey identifies a sell order within a shop
+type orderKey struct {
+	itemID              int32
+	currencyID          int32
+	itemIsBlueprint     bool
+	currencyIsBlueprint bool
+}
+
+// keyOf returns the key of a sell order
+func keyOf(order rustplus.SellOrder) orderKey {
+	return orderKey{
+		itemID:              order.ItemID,
+		currencyID:          order.CurrencyID,
+		itemIsBlueprint:     order.ItemIsBlueprint,
+		currencyIsBlueprint: order.CurrencyIsBlueprint,
+	}
+}
+
+// Diff returns the events that turn the previous snapshot into the current one
+func Diff(previous, current *Snapshot) []Event {
+	var events []Event
+
+	previousShops := make(map[uint32]Shop, len(previous.Shops))
+	for _, shop := range previous.Shops {
+		previousShops[shop.ID] = shop
+	}
+
+	currentShops := make(map[uint32]bool, len(current.Shops))
+	for _, shop := range current.Shops {
+		currentShops[shop.ID] = true
+
+		old, ok := previousShops[shop.ID]
+		if !ok {
+			events = append(events, Event{Type: EventShopOpened, Time: current.Time, Shop: shop})
+			continue
+		}
+
+		events = append(events, diffOrders(current.Time, old, shop)...)
+	}
+
+	for _, shop := range previous.Shops {
+		if !currentShops[shop.ID] {
+			events = append(events, Event{Type: EventShopClosed, Time: current.Time, Shop: shop})
+		}
+	}
+
+	return events
+}
+
+// diffOrders returns the order events between two snapshots of a shop
+func diffOrders(at time.Time, previous, current Shop) []Event {
+	var events []Event
+
+	previousOrders := make(map[orderKey]rustplus.SellOrder, len(previous.Orders))
+	for _, order := range previous.Orders {
+		previousOrders[keyOf(order)] = order
+	}
+
+	currentOrders := make(map[orderKey]bool, len(current.Orders))
+	for _, order := range current.Orders {
+		order := order
+		currentOrders[keyOf(order)] = true
+
+		old, ok := previousOrders[keyOf(order)]
+		if !ok {
+			events = append(events, Event{Type: EventOrderAdded, Time: at, Shop: current, Order: &order})
+			continue
+		}
+
+		event := Event{Time: at, Shop: current, Order: &order, Previous: &old}
+		if old.Quantity != order.Quantity || old.CostPerItem != order.CostPerItem {
+			event.Type = EventPriceChanged
+			events = append(events, event)
+		}
+
+		switch {
+		case old.InStock() && !order.InStock():
+			event.Type = EventOutOfStock
+			events = append(events, event)
+		case !old.InStock() && order.InStock():
+			event.Type = EventRestocked
+			events = append(events, event)
+		}
+	}
+
+	for _, order := range previous.Orders {
+		order := order
+		if !currentOrders[keyOf(order)] {
+			events = append(events, Event{Type: EventOrderRemoved, Time: at, Shop: current, Order: &order})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,145 @@
+// Package market tracks vending machine shops over time from the map
+// markers, recording price and stock history and reporting changes.
+package market
+
+import (
+	"sort"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+)
+
+// AnyCurrency matches sell orders priced in any currency
+const AnyCurrency int32 = 0
+
+// Shop is a vending machine as seen in one snapshot
+type Shop struct {
+	ID         uint32               `json:"id"`
+	Name       string               `json:"name"`
+	X          float32              `json:"x"`
+	Y          float32              `json:"y"`
+	OutOfStock bool                 `json:"outOfStock"`
+	Orders     []rustplus.SellOrder `json:"orders"`
+}
+
+// Snapshot is the state of every shop on the map at a point in time
+type Snapshot struct {
+	Time  time.Time `json:"time"`
+	Shops []Shop    `json:"shops"`
+}
+
+// NewSnapshot creates a snapshot of the vending machines in the markers
+func NewSnapshot(at time.Time, markers *rustplus.MapMarkers) *Snapshot {
+	snapshot := &Snapshot{Time: at}
+
+	for _, vm := range markers.VendingMachines() {
+		snapshot.Shops = append(snapshot.Shops, Shop{
+			ID:         vm.ID,
+			Name:       vm.Name,
+			X:          vm.X,
+			Y:          vm.Y,
+			OutOfStock: vm.OutOfStock,
+			Orders:     vm.SellOrders,
+		})
+	}
+
+	return snapshot
+}
+
+// Shop returns the shop with the given marker ID
+func (s *Snapshot) Shop(id uint32) (Shop, bool) {
+	for _, shop := range s.Shops {
+		if shop.ID == id {
+			return shop, true
+		}
+	}
+	return Shop{}, false
+}
+
+// Offer is a sell order of a shop
+type Offer struct {
+	Shop  Shop
+	Order rustplus.SellOrder
+	// UnitPrice is the amount of currency paid per item
+	UnitPrice float64
+}
+
+// Offers returns the in-stock sell orders of an item priced in the given
+// currency, or in any currency if currencyID is AnyCurrency, cheapest first
+func (s *Snapshot) Offers(itemID, currencyID int32) []Offer {
+	var offers []Offer
+	for _, shop := range s.Shops {
+		for _, order := range shop.Orders {
+			if order.ItemID != itemID || !order.InStock() || order.Quantity <= 0 {
+				continue
+			}
+			if currencyID != AnyCurrency && order.CurrencyID != currencyID {
+				continue
+			}
+
+			offers = append(offers, Offer{
+				Shop:      shop,
+				Order:     order,
+				UnitPrice: UnitPrice(order),
+			})
+		}
+	}
+
+	sort.SliceStable(offers, func(i, j int) bool {
+		return offers[i].UnitPrice < offers[j].UnitPrice
+	})
+	return offers
+}
+
+// Cheapest returns the cheapest in-stock source of an item in the given currency
+func (s *Snapshot) Cheapest(itemID, currencyID int32) (Offer, bool) {
+	offers := s.Offers(itemID, currencyID)
+	if len(offers) == 0 {
+		return Offer{}, false
+	}
+	return offers[0], true
+}
+
+// UnitPrice returns the amount of currency a sell order charges per item
+func UnitPrice(order rustplus.SellOrder) float64 {
+	if order.Quantity <= 0 {
+		return 0
+	}
+	return float64(order.CostPerItem) / float64(order.Quantity)
+}
+
+// PricePoint is the price and stock of one sell order at a point in time
+type PricePoint struct {
+	Time          time.Time
+	ShopID        uint32
+	ItemID        int32
+	CurrencyID    int32
+	Quantity      int32
+	CostPerItem   int32
+	AmountInStock int32
+	UnitPrice     float64
+}
+
+// pricePoints returns the price points of an item in a snapshot
+func (s *Snapshot) pricePoints(itemID int32) []PricePoint {
+	var points []PricePoint
+	for _, shop := range s.Shops {
+		for _, order := range shop.Orders {
+			if order.ItemID != itemID {
+				continue
+			}
+
+			points = append(points, PricePoint{
+				Time:          s.Time,
+				ShopID:        shop.ID,
+				ItemID:        order.ItemID,
+				CurrencyID:    order.CurrencyID,
+				Quantity:      order.Quantity,
+				CostPerItem:   order.CostPerItem,
+				AmountInStock: order.AmountInStock,
+				UnitPrice:     UnitPrice(order),
+			})
+		}
+	}
+	return points
+}
@@ -0,0 +1,205 @@
+package market
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	"github.com/chickenfresh/go-rustplus/rustplus/rustplustest"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	sulfurOre = -1157596551
+	scrap     = -932201673
+)
+
+// shopMarker creates a vending machine marker selling sulfur ore for scrap
+func shopMarker(id uint32, quantity, cost, stock int32) *proto.AppMarker {
+	return &proto.AppMarker{
+		Id:   protobuf.Uint32(id),
+		Type: proto.AppMarkerType_VendingMachine.Enum(),
+		X:    protobuf.Float32(100),
+		Y:    protobuf.Float32(200),
+		Name: protobuf.String("Shop"),
+		SellOrders: []*proto.AppMarker_SellOrder{{
+			ItemId:        protobuf.Int32(sulfurOre),
+			Quantity:      protobuf.Int32(quantity),
+			CurrencyId:    protobuf.Int32(scrap),
+			CostPerItem:   protobuf.Int32(cost),
+			AmountInStock: protobuf.Int32(stock),
+		}},
+	}
+}
+
+// TestTracker_Poll tests detecting shop changes and answering price queries
+func TestTracker_Poll(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	path := filepath.Join(t.TempDir(), "market.jsonl")
+	store, err := OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	tracker := NewTracker(client, store)
+
+	server.SetMarkers([]*proto.AppMarker{shopMarker(1, 1000, 50, 5)})
+	if events, err := tracker.Poll(context.Background()); err != nil || len(events) != 0 {
+		t.Fatalf("Expected no events on the first poll, got %v, %v", events, err)
+	}
+
+	server.SetMarkers([]*proto.AppMarker{shopMarker(1, 1000, 60, 0), shopMarker(2, 500, 20, 3)})
+	events, err := tracker.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[EventType]bool{EventShopOpened: true, EventPriceChanged: true, EventOutOfStock: true}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), events)
+	}
+	for _, event := range events {
+		if !want[event.Type] {
+			t.Errorf("Unexpected event %s", event.Type)
+		}
+	}
+
+	offer, ok := tracker.Cheapest(sulfurOre, scrap)
+	if !ok || offer.Shop.ID != 2 || offer.UnitPrice != 0.04 {
+		t.Errorf("Unexpected cheapest offer %+v", offer)
+	}
+	if _, ok := tracker.Cheapest(sulfurOre, 12345); ok {
+		t.Error("Expected no offer in another currency")
+	}
+
+	history, err := tracker.History(sulfurOre, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history) != 3 {
+		t.Errorf("Expected 3 price points, got %d", len(history))
+	}
+
+	// Snapshots are reloaded from the file
+	reopened, err := OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	latest, err := reopened.Latest()
+	if err != nil || latest == nil || len(latest.Shops) != 2 {
+		t.Errorf("Expected the latest snapshot to have 2 shops, got %+v, %v", latest, err)
+	}
+}
+
+// TestFileStore_Compact tests that the file is compacted to the limit
+func TestFileStore_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "market.jsonl")
+	store, err := OpenFileStore(path, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if err := store.Save(&Snapshot{Time: start.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if records := bytes.Count(data, []byte("\n")); records > 6 {
+			t.Fatalf("Expected at most 6 records on disk, got %d", records)
+		}
+	}
+	store.Close()
+
+	store, err = OpenFileStore(path, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	latest, err := store.Latest()
+	if err != nil || latest == nil || !latest.Time.Equal(start.Add(9*time.Minute)) {
+		t.Errorf("Unexpected latest snapshot %+v, %v", latest, err)
+	}
+	if len(store.snapshots) != 3 {
+		t.Errorf("Expected 3 snapshots, got %d", len(store.snapshots))
+	}
+}
+
+// TestFileStore_TornSnapshot tests that a snapshot cut short by a crash is
+// dropped and later snapshots are kept intact
+func TestFileStore_TornSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "market.jsonl")
+	store, err := OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	start := time.Now()
+	if err := store.Save(&Snapshot{Time: start}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	file.WriteString(`{"time":"20`)
+	file.Close()
+
+	for i := 1; i <= 2; i++ {
+		store, err = OpenFileStore(path, 0)
+		if err != nil {
+			t.Fatalf("Failed to reopen store: %v", err)
+		}
+		if len(store.snapshots) != i {
+			t.Fatalf("Expected %d snapshots, got %d", i, len(store.snapshots))
+		}
+		if err := store.Save(&Snapshot{Time: start.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		store.Close()
+	}
+}
+
+// TestTracker_UpdateFailed tests that a snapshot that could not be saved does
+// not become the baseline
+func TestTracker_UpdateFailed(t *testing.T) {
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "market.jsonl"), 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tracker := NewTracker(nil, store)
+
+	first := &Snapshot{Time: time.Now()}
+	if _, err := tracker.Update(first); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	store.Close()
+	if _, err := tracker.Update(&Snapshot{Time: time.Now()}); err == nil {
+		t.Fatal("Expected saving to a closed store to fail")
+	}
+	if latest := tracker.Latest(); latest != first {
+		t.Errorf("Expected the saved snapshot to stay the baseline, got %+v", latest)
+	}
+}
@@ -0,0 +1,225 @@
+package market
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/internal/atomicfile"
+)
+
+// errStoreClosed is returned when using a closed file store
+var errStoreClosed = errors.New("market store closed")
+
+// Store persists market snapshots
+type Store interface {
+	// Save records a snapshot
+	Save(snapshot *Snapshot) error
+	// Latest returns the most recent snapshot, or nil if there are none
+	Latest() (*Snapshot, error)
+	// History returns the price points of an item between from and to
+	History(itemID int32, from, to time.Time) ([]PricePoint, error)
+}
+
+// MemoryStore keeps snapshots in memory
+type MemoryStore struct {
+	snapshots []*Snapshot
+	limit     int
+	mutex     sync.RWMutex
+}
+
+// NewMemoryStore creates a store that keeps up to limit snapshots, dropping
+// the oldest first. A limit of zero keeps every snapshot.
+func NewMemoryStore(limit int) *MemoryStore {
+	return &MemoryStore{limit: limit}
+}
+
+// Save records a snapshot
+func (s *MemoryStore) Save(snapshot *Snapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.snapshots = append(s.snapshots, snapshot)
+	if s.limit > 0 && len(s.snapshots) > s.limit {
+		s.snapshots = append([]*Snapshot(nil), s.snapshots[len(s.snapshots)-s.limit:]...)
+	}
+	return nil
+}
+
+// Latest returns the most recent snapshot, or nil if there are none
+func (s *MemoryStore) Latest() (*Snapshot, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if len(s.snapshots) == 0 {
+		return nil, nil
+	}
+	return s.snapshots[len(s.snapshots)-1], nil
+}
+
+// History returns the price points of an item between from and to
+func (s *MemoryStore) History(itemID int32, from, to time.Time) ([]PricePoint, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var points []PricePoint
+	for _, snapshot := range s.snapshots {
+		if snapshot.Time.Before(from) || snapshot.Time.After(to) {
+			continue
+		}
+		points = append(points, snapshot.pricePoints(itemID)...)
+	}
+	return points, nil
+}
+
+// FileStore keeps snapshots in memory and appends them to a file with one
+// JSON snapshot per line, so history survives restarts. With a limit, the
+// file is rewritten with only the kept snapshots once it holds twice as many,
+// so it never grows beyond twice the limit.
+type FileStore struct {
+	*MemoryStore
+	path string
+	file *os.File
+	// records is the number of snapshots in the file
+	records int
+	// writeMutex keeps lines from interleaving
+	writeMutex sync.Mutex
+}
+
+// OpenFileStore opens or creates a snapshot file and loads the snapshots in it
+func OpenFileStore(path string, limit int) (*FileStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read market store: %w", err)
+	}
+
+	store := &FileStore{
+		MemoryStore: NewMemoryStore(limit),
+		path:        path,
+	}
+
+	// A crash while appending can only tear the last snapshot, which is
+	// dropped if it cannot be parsed
+	torn := len(data) > 0 && !bytes.HasSuffix(data, []byte("\n"))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var snapshot Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			if torn && bytes.HasSuffix(data, scanner.Bytes()) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse market store at line %d: %w", line, err)
+		}
+		store.MemoryStore.Save(&snapshot)
+		store.records++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read market store: %w", err)
+	}
+
+	store.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open market store: %w", err)
+	}
+
+	// Rewrite a torn file, whose last line the next snapshot would otherwise
+	// be appended to
+	if torn || (limit > 0 && store.records > limit) {
+		if err := store.compact(); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// Save records a snapshot and appends it to the file
+func (s *FileStore) Save(snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	if s.file == nil {
+		return errStoreClosed
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	s.records++
+
+	if err := s.MemoryStore.Save(snapshot); err != nil {
+		return err
+	}
+
+	// The snapshot is saved even if compacting fails, which leaves the file
+	// as it was to be compacted on the next save
+	if s.limit > 0 && s.records >= 2*s.limit {
+		s.compact()
+	}
+	return nil
+}
+
+// compact replaces the file with the snapshots kept in memory. The write
+// mutex must be held.
+func (s *FileStore) compact() error {
+	s.mutex.RLock()
+	var buf bytes.Buffer
+	for _, snapshot := range s.snapshots {
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			s.mutex.RUnlock()
+			return fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	records := len(s.snapshots)
+	s.mutex.RUnlock()
+
+	// The temporary file becomes the store, so keep appending to it
+	tmp, err := atomicfile.Create(s.path, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to compact market store: %w", err)
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Discard()
+		return fmt.Errorf("failed to compact market store: %w", err)
+	}
+	if err := tmp.Commit(); err != nil {
+		return fmt.Errorf("failed to compact market store: %w", err)
+	}
+
+	s.file.Close()
+	s.file = tmp.File
+	s.records = records
+	return nil
+}
+
+// Close closes the file
+func (s *FileStore) Close() error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	if s.file == nil {
+		return errStoreClosed
+	}
+
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,178 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+)
+
+// DefaultPollInterval is how often a started tracker polls the map markers
+const DefaultPollInterval = time.Minute
+
+// Tracker polls the map markers, stores a snapshot of the shops each time
+// and emits the changes since the previous snapshot
+type Tracker struct {
+	client    *rustplus.Client
+	store     Store
+	latest    *Snapshot
+	loaded    bool
+	mutex     sync.RWMutex
+	pollMutex sync.Mutex
+	eventChan chan Event
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewTracker creates a tracker that records snapshots in store. A nil store
+// keeps snapshots in memory.
+func NewTracker(client *rustplus.Client, store Store) *Tracker {
+	if store == nil {
+		store = NewMemoryStore(0)
+	}
+
+	return &Tracker{
+		client:    client,
+		store:     store,
+		eventChan: make(chan Event, 100),
+	}
+}
+
+// Events returns a channel of market events
+func (t *Tracker) Events() <-chan Event {
+	return t.eventChan
+}
+
+// Poll fetches the map markers, stores a snapshot and returns the changes
+// since the previous one. The first poll is compared with the latest stored
+// snapshot, if any.
+func (t *Tracker) Poll(ctx context.Context) ([]Event, error) {
+	t.pollMutex.Lock()
+	defer t.pollMutex.Unlock()
+
+	markers, err := t.client.GetMapMarkersContext(rustplus.WithPriority(ctx, rustplus.PriorityBulk))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get map markers: %w", err)
+	}
+
+	return t.Update(NewSnapshot(time.Now(), markers))
+}
+
+// Update stores a snapshot and returns the changes since the previous one
+func (t *Tracker) Update(snapshot *Snapshot) ([]Event, error) {
+	t.mutex.Lock()
+	if !t.loaded {
+		stored, err := t.store.Latest()
+		if err != nil {
+			t.mutex.Unlock()
+			return nil, fmt.Errorf("failed to load latest snapshot: %w", err)
+		}
+		t.latest = stored
+		t.loaded = true
+	}
+	previous := t.latest
+
+	// The snapshot only becomes the baseline once it is stored, so the next
+	// update is compared with what a restart would load
+	if err := t.store.Save(snapshot); err != nil {
+		t.mutex.Unlock()
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	t.latest = snapshot
+	t.mutex.Unlock()
+
+	// Without a baseline every shop would be reported as opened
+	if previous == nil {
+		return nil, nil
+	}
+
+	events := Diff(previous, snapshot)
+	for _, event := range events {
+		select {
+		case t.eventChan <- event:
+			// Event sent successfully
+		default:
+			// Channel is full, log the error
+			fmt.Printf("Warning: Market event channel is full, event %s dropped\n", event.Type)
+		}
+	}
+
+	return events, nil
+}
+
+// Start polls the map markers every interval until Stop is called or ctx is
+// done. An interval of zero uses DefaultPollInterval.
+func (t *Tracker) Start(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	t.mutex.Lock()
+	if t.cancel != nil {
+		t.mutex.Unlock()
+		return fmt.Errorf("market tracker already started")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	done := t.done
+	t.mutex.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := t.Poll(ctx); err != nil && ctx.Err() == nil {
+				fmt.Printf("Warning: Market poll failed: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops polling and waits for the current poll to finish
+func (t *Tracker) Stop() {
+	t.mutex.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	t.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Latest returns the most recent snapshot, or nil before the first poll
+func (t *Tracker) Latest() *Snapshot {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.latest
+}
+
+// Cheapest returns the cheapest in-stock source of an item in the given
+// currency from the most recent snapshot
+func (t *Tracker) Cheapest(itemID, currencyID int32) (Offer, bool) {
+	latest := t.Latest()
+	if latest == nil {
+		return Offer{}, false
+	}
+	return latest.Cheapest(itemID, currencyID)
+}
+
+// History returns the stored price points of an item since the given time
+func (t *Tracker) History(itemID int32, since time.Time) ([]PricePoint, error) {
+	return t.store.History(itemID, since, time.Now())
+}
@@ -0,0 +1,69 @@
+package rustplus
+
+import (
+	"strings"
+)
+
+// monumentNames maps monument tokens sent in AppMap to their in-game names
+var monumentNames = map[string]string{
+	"AbandonedMilitaryBase":              "Abandoned Military Base",
+	"airfield_display_name":              "Airfield",
+	"arctic_base_a":                      "Arctic Research Base",
+	"bandit_camp":                        "Bandit Camp",
+	"dome_monument_name":                 "The Dome",
+	"excavator":                          "Giant Excavator Pit",
+	"ferryterminal":                      "Ferry Terminal",
+	"fishing_village_display_name":       "Fishing Village",
+	"gas_station":                        "Oxum's Gas Station",
+	"harbor_2_display_name":              "Harbor",
+	"harbor_display_name":                "Harbor",
+	"junkyard_display_name":              "Junkyard",
+	"large_barn_display_name":            "Large Barn",
+	"large_fishing_village_display_name": "Large Fishing Village",
+	"large_oil_rig":                      "Large Oil Rig",
+	"launchsite":                         "Launch Site",
+	"lighthouse_display_name":            "Lighthouse",
+	"military_tunnels_display_name":      "Military Tunnel",
+	"mining_outpost_display_name":        "Mining Outpost",
+	"mining_quarry_hqm_display_name":     "HQM Quarry",
+	"mining_quarry_stone_display_name":   "Stone Quarry",
+	"mining_quarry_sulfur_display_name":  "Sulfur Quarry",
+	"missile_silo_monument":              "Missile Silo",
+	"oil_rig_small":                      "Oil Rig",
+	"outpost":                            "Outpost",
+	"power_plant_display_name":           "Power Plant",
+	"ranch_display_name":                 "Ranch",
+	"satellite_dish_display_name":        "Satellite Dish",
+	"sewer_display_name":                 "Sewer Branch",
+	"stables_a":                          "Stables",
+	"stables_b":                          "Barn",
+	"supermarket":                        "Abandoned Supermarket",
+	"swamp_c":                            "Abandoned Cabins",
+	"train_tunnel_display_name":          "Train Tunnel",
+	"train_tunnel_link_display_name":     "Train Tunnel",
+	"train_yard_display_name":            "Train Yard",
+	"underwater_lab":                     "Underwater Lab",
+	"water_treatment_plant_display_name": "Water Treatment Plant",
+	"DungeonBase":                        "Dungeon",
+}
+
+// MonumentName returns the in-game name of a monument token. Unknown tokens
+// are turned into a readable name by dropping the "_display_name" suffix.
+func MonumentName(token string) string {
+	if name, ok := monumentNames[token]; ok {
+		return name
+	}
+
+	name := strings.TrimSuffix(token, "_display_name")
+	words := strings.Fields(strings.ReplaceAll(name, "_", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// IsTrainTunnel reports whether a monument token is a train tunnel entrance,
+// which maps usually leave out as there are many of them
+func IsTrainTunnel(token string) bool {
+	return strings.HasPrefix(token, "train_tunnel")
+}
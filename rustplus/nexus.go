@@ -0,0 +1,264 @@
+package rustplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// ErrUnknownZone is returned when a Nexus zone has not been registered
+var ErrUnknownZone = errors.New("unknown nexus zone")
+
+// GetNexusAuth exchanges a Nexus app key for a player token valid on the
+// zone server the key belongs to
+func (c *Client) GetNexusAuth(appKey string) (*proto.AppNexusAuth, error) {
+	return c.GetNexusAuthContext(context.Background(), appKey)
+}
+
+// GetNexusAuthContext exchanges a Nexus app key for a player token using the
+// provided context
+func (c *Client) GetNexusAuthContext(ctx context.Context, appKey string) (*proto.AppNexusAuth, error) {
+	request := &proto.AppRequest{
+		GetNexusAuth: &proto.AppGetNexusAuth{
+			AppKey: protobuf.String(appKey),
+		},
+	}
+
+	response, err := c.request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.NexusAuth == nil {
+		return nil, ErrInvalidResponse
+	}
+
+	return response.NexusAuth, nil
+}
+
+// NexusZone describes a zone server of a Nexus cluster
+type NexusZone struct {
+	// Name is the zone name reported in AppInfo.NexusZone
+	Name string
+	// AppKey is exchanged with GetNexusAuth for a player token on the zone
+	AppKey string
+	Server string
+	Port   int
+}
+
+// NexusClient holds connections to the zone servers of a Nexus cluster. The
+// home zone is connected with the paired player token and tokens for the
+// other zones are obtained from it with GetNexusAuth.
+type NexusClient struct {
+	home              *Client
+	homeZone          string
+	playerID          uint64
+	useFacepunchProxy bool
+	opts              []ClientOption
+	zones             map[string]NexusZone
+	clients           map[string]*Client
+	dials             map[string]*zoneDial
+	closed            bool
+	mutex             sync.Mutex
+}
+
+// zoneDial is a connection to a zone in progress, shared by every caller
+// asking for the zone meanwhile
+type zoneDial struct {
+	done   chan struct{}
+	client *Client
+	err    error
+}
+
+// NewNexusClient creates a Nexus client whose home zone is the paired server
+func NewNexusClient(server string, port int, playerID uint64, playerToken int, useFacepunchProxy bool, opts ...ClientOption) *NexusClient {
+	return &NexusClient{
+		home:              NewClient(server, port, playerID, playerToken, useFacepunchProxy, opts...),
+		playerID:          playerID,
+		useFacepunchProxy: useFacepunchProxy,
+		opts:              opts,
+		zones:             make(map[string]NexusZone),
+		clients:           make(map[string]*Client),
+		dials:             make(map[string]*zoneDial),
+	}
+}
+
+// Connect connects to the home zone and learns its zone name from AppInfo
+func (n *NexusClient) Connect(ctx context.Context) error {
+	if err := n.home.ConnectContext(ctx); err != nil {
+		return err
+	}
+
+	info, err := n.home.GetInfoContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get home zone info: %w", err)
+	}
+
+	n.mutex.Lock()
+	n.homeZone = info.GetNexusZone()
+	n.clients[n.homeZone] = n.home
+	n.mutex.Unlock()
+
+	return nil
+}
+
+// Home returns the client connected to the home zone
+func (n *NexusClient) Home() *Client {
+	return n.home
+}
+
+// HomeZone returns the name of the home zone, known once connected
+func (n *NexusClient) HomeZone() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.homeZone
+}
+
+// AddZone registers a zone server so requests can be routed to it
+func (n *NexusClient) AddZone(zone NexusZone) {
+	n.mutex.Lock()
+	n.zones[zone.Name] = zone
+	n.mutex.Unlock()
+}
+
+// Zones returns the names of the registered zones
+func (n *NexusClient) Zones() []string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	names := make([]string, 0, len(n.zones))
+	for name := range n.zones {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Zone returns the client connected to the named zone, authenticating and
+// connecting to it on first use. Zones connect independently, so a slow zone
+// does not hold up the others.
+func (n *NexusClient) Zone(ctx context.Context, name string) (*Client, error) {
+	for {
+		n.mutex.Lock()
+		if n.closed {
+			n.mutex.Unlock()
+			return nil, ErrClientClosed
+		}
+		if client, ok := n.clients[name]; ok {
+			n.mutex.Unlock()
+			return client, nil
+		}
+
+		// Wait for a connection already in progress
+		if dial, ok := n.dials[name]; ok {
+			n.mutex.Unlock()
+
+			select {
+			case <-dial.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+
+			// Only give up on the other caller's failure if it was not
+			// caused by its own context
+			if dial.err != nil && !errors.Is(dial.err, context.Canceled) && !errors.Is(dial.err, context.DeadlineExceeded) {
+				return nil, dial.err
+			}
+			continue
+		}
+
+		zone, ok := n.zones[name]
+		if !ok {
+			n.mutex.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrUnknownZone, name)
+		}
+
+		dial := &zoneDial{done: make(chan struct{})}
+		n.dials[name] = dial
+		n.mutex.Unlock()
+
+		dial.client, dial.err = n.connectZone(ctx, zone)
+
+		n.mutex.Lock()
+		delete(n.dials, name)
+		if dial.err == nil {
+			if n.closed {
+				// Close ran while connecting
+				dial.client.Close()
+				dial.client, dial.err = nil, ErrClientClosed
+			} else {
+				n.clients[name] = dial.client
+			}
+		}
+		n.mutex.Unlock()
+		close(dial.done)
+
+		return dial.client, dial.err
+	}
+}
+
+// connectZone authenticates with a zone through the home zone and connects
+// to it
+func (n *NexusClient) connectZone(ctx context.Context, zone NexusZone) (*Client, error) {
+	// Exchange the zone's app key for a player token through the home zone
+	auth, err := n.home.GetNexusAuthContext(ctx, zone.AppKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with zone %s: %w", zone.Name, err)
+	}
+
+	client := NewClient(zone.Server, zone.Port, n.playerID, int(auth.GetPlayerToken()), n.useFacepunchProxy, n.opts...)
+	if err := client.ConnectContext(ctx); err != nil {
+		// Release the client's resources
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to zone %s: %w", zone.Name, err)
+	}
+
+	return client, nil
+}
+
+// Do routes a call to the client connected to the named zone
+func (n *NexusClient) Do(ctx context.Context, zone string, fn func(*Client) error) error {
+	client, err := n.Zone(ctx, zone)
+	if err != nil {
+		return err
+	}
+	return fn(client)
+}
+
+// CloseZone disconnects from a zone other than the home zone
+func (n *NexusClient) CloseZone(name string) error {
+	n.mutex.Lock()
+	client, ok := n.clients[name]
+	if ok && client != n.home {
+		delete(n.clients, name)
+	}
+	n.mutex.Unlock()
+
+	if !ok || client == n.home {
+		return nil
+	}
+	return client.Close()
+}
+
+// Close disconnects from every zone. Zones cannot be used afterwards.
+func (n *NexusClient) Close() error {
+	n.mutex.Lock()
+	n.closed = true
+	clients := n.clients
+	n.clients = make(map[string]*Client)
+	n.mutex.Unlock()
+
+	var errs []error
+	for _, client := range clients {
+		if client == n.home {
+			continue
+		}
+		errs = append(errs, client.Close())
+	}
+	errs = append(errs, n.home.Close())
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,32 @@
+package rustplus
+
+import "time"
+
+// DefaultRequestTimeout is the timeout applied to requests whose context has no deadline
+const DefaultRequestTimeout = 10 * time.Second
+
+// ClientOption configures optional Client behaviour
+type ClientOption func(*Client)
+
+// WithRequestTimeout sets the timeout applied to requests whose context has
+// no deadline. A timeout of zero disables the default timeout.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithReconnectPolicy sets the policy used to reconnect after the connection is lost
+func WithReconnectPolicy(policy ReconnectPolicy) ClientOption {
+	return func(c *Client) {
+		c.reconnectPolicy = policy
+	}
+}
+
+// WithRateLimiter queues requests through the given rate limiter before
+// sending them, so the server does not reject them for exceeding its limits
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
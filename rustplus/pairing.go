@@ -0,0 +1,165 @@
+package rustplus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// ErrNotPairing is returned when parsing a notification that is not a pairing
+var ErrNotPairing = errors.New("not a pairing notification")
+
+// PairingChannel is the channel ID of pairing notifications
+const PairingChannel = "pairing"
+
+// PairingType says what was paired
+type PairingType string
+
+const (
+	// PairingServer is sent when pairing with a server from the in-game menu
+	PairingServer PairingType = "server"
+	// PairingEntity is sent when pairing a smart switch, alarm or storage monitor
+	PairingEntity PairingType = "entity"
+)
+
+// PairingNotification is a server or entity pairing sent over FCM. Both
+// carry the server credentials; entity pairings also carry the entity.
+type PairingNotification struct {
+	Type PairingType
+	ServerConfig
+	// ServerID is the ID Facepunch gives the server
+	ServerID    string
+	Name        string
+	Description string
+	Image       string
+	Logo        string
+	URL         string
+	// Entity is set for entity pairings
+	Entity *PairedEntity
+}
+
+// PairedEntity is the smart device in an entity pairing
+type PairedEntity struct {
+	ID   uint32
+	Type proto.AppEntityType
+	Name string
+}
+
+// IsEntity reports whether the notification pairs an entity
+func (p *PairingNotification) IsEntity() bool {
+	return p.Type == PairingEntity
+}
+
+// ParsePairing parses the data of a pairing notification from FCM. The body
+// is read from the "data" object, as sent to the companion app, or from the
+// notification itself. ErrNotPairing is returned for other notifications.
+func ParsePairing(notification map[string]interface{}) (*PairingNotification, error) {
+	data, ok := notification["data"].(map[string]interface{})
+	if !ok {
+		data = notification
+	}
+
+	if channel, ok := data["channelId"].(string); ok && channel != PairingChannel {
+		return nil, fmt.Errorf("channel %q: %w", channel, ErrNotPairing)
+	}
+
+	// The body is a JSON string
+	bodyStr, ok := data["body"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid notification format: missing body")
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(bodyStr), &body); err != nil {
+		return nil, fmt.Errorf("invalid notification format: body is not valid JSON: %w", err)
+	}
+
+	p := &PairingNotification{
+		Type:        PairingType(field(body, "type")),
+		ServerID:    field(body, "id"),
+		Name:        field(body, "name"),
+		Description: field(body, "desc"),
+		Image:       field(body, "img"),
+		Logo:        field(body, "logo"),
+		URL:         field(body, "url"),
+	}
+
+	// Older notifications have no type and are server pairings
+	switch p.Type {
+	case "":
+		p.Type = PairingServer
+	case PairingServer, PairingEntity:
+	default:
+		return nil, fmt.Errorf("pairing type %q: %w", p.Type, ErrNotPairing)
+	}
+
+	// The address is either separate ip and port fields or "ip:port"
+	port := field(body, "port")
+	p.Server = field(body, "ip")
+	if server := field(body, "server"); p.Server == "" && server != "" {
+		host, serverPort, err := net.SplitHostPort(server)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server format: %s", server)
+		}
+		p.Server, port = host, serverPort
+	}
+	if p.Server == "" {
+		return nil, fmt.Errorf("invalid notification format: missing server")
+	}
+
+	var err error
+	if p.Port, err = strconv.Atoi(port); err != nil {
+		return nil, fmt.Errorf("invalid server format: port is not a valid number: %w", err)
+	}
+
+	playerID := field(body, "playerId")
+	if playerID == "" {
+		return nil, fmt.Errorf("invalid notification format: missing playerId")
+	}
+	if p.PlayerID, err = strconv.ParseUint(playerID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid notification format: playerId is not a valid number: %w", err)
+	}
+
+	playerToken := field(body, "playerToken")
+	if playerToken == "" {
+		return nil, fmt.Errorf("invalid notification format: missing playerToken")
+	}
+	if p.PlayerToken, err = strconv.Atoi(playerToken); err != nil {
+		return nil, fmt.Errorf("invalid notification format: playerToken is not a valid number: %w", err)
+	}
+
+	if p.Type == PairingEntity {
+		entityID, err := strconv.ParseUint(field(body, "entityId"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification format: entityId is not a valid number: %w", err)
+		}
+		entityType, err := strconv.Atoi(field(body, "entityType"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification format: entityType is not a valid number: %w", err)
+		}
+
+		p.Entity = &PairedEntity{
+			ID:   uint32(entityID),
+			Type: proto.AppEntityType(entityType),
+			Name: field(body, "entityName"),
+		}
+	}
+
+	return p, nil
+}
+
+// field returns a string or number field of a JSON object as a string
+func field(body map[string]interface{}, key string) string {
+	switch v := body[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
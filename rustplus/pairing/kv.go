@@ -0,0 +1,310 @@
+package pairing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/internal/atomicfile"
+)
+
+// Keys in a KV store
+const (
+	kvServerPrefix       = "server/"
+	kvCredentials        = "credentials"
+	kvPersistentIDPrefix = "persistentId/"
+	// kvPersistentIDs is the single list of persistent IDs kept by older
+	// versions, converted to one key per ID when the store is opened
+	kvPersistentIDs   = "persistentIds"
+	kvCompactInterval = 1000
+)
+
+// errKVClosed is returned when writing to a closed KV store
+var errKVClosed = errors.New("kv store closed")
+
+// kvRecord is a line in a KV store's log. A nil value deletes the key.
+type kvRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// KVStore is a Store kept in an embedded key-value log. Each change appends
+// only the keys that changed, which suits frequently recorded persistent IDs
+// better than rewriting a whole JSON file: each ID is a key of its own, so
+// recording one appends a single small record, and evicting one a delete.
+// The log is compacted when it grows much larger than its contents.
+type KVStore struct {
+	*MemoryStore
+	path    string
+	file    *os.File
+	values  map[string][]byte
+	records int
+	// sequence orders the persistent IDs, which the log does not keep in order
+	sequence map[string]uint64
+	next     uint64
+}
+
+// OpenKVStore opens the KV store at path, creating it if needed
+func OpenKVStore(path string) (*KVStore, error) {
+	s := &KVStore{MemoryStore: NewMemoryStore(), path: path, values: make(map[string][]byte), sequence: make(map[string]uint64)}
+	s.persist = s.write
+
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+
+	state, err := s.state()
+	if err != nil {
+		return nil, err
+	}
+	s.load(state)
+
+	// Start from a compact log in the current format, which also drops a
+	// torn final record
+	values, sequence, next, err := s.encode(state)
+	if err != nil {
+		return nil, err
+	}
+	s.values, s.sequence, s.next = values, sequence, next
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close closes the log
+func (s *KVStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// replay reads the log into values
+func (s *KVStore) replay() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read store: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		var record kvRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// A crash while appending can only tear the last record
+			if !bytes.HasSuffix(data, []byte("\n")) && bytes.HasSuffix(data, scanner.Bytes()) {
+				break
+			}
+			return fmt.Errorf("failed to parse store at line %d: %w", line, err)
+		}
+
+		if record.Value == nil {
+			delete(s.values, record.Key)
+		} else {
+			s.values[record.Key] = record.Value
+		}
+	}
+
+	return scanner.Err()
+}
+
+// state decodes the values into a store state
+func (s *KVStore) state() (storeState, error) {
+	var state storeState
+	type persistentID struct {
+		id       string
+		sequence uint64
+	}
+	var persistentIDs []persistentID
+
+	for key, value := range s.values {
+		var err error
+		switch {
+		case strings.HasPrefix(key, kvServerPrefix):
+			var server Server
+			err = json.Unmarshal(value, &server)
+			state.Servers = append(state.Servers, server)
+		case strings.HasPrefix(key, kvPersistentIDPrefix):
+			id := persistentID{id: strings.TrimPrefix(key, kvPersistentIDPrefix)}
+			err = json.Unmarshal(value, &id.sequence)
+			persistentIDs = append(persistentIDs, id)
+		case key == kvCredentials:
+			state.Credentials = &fcm.Credentials{}
+			err = json.Unmarshal(value, state.Credentials)
+		case key == kvPersistentIDs:
+			err = json.Unmarshal(value, &state.PersistentIDs)
+		}
+		if err != nil {
+			return state, fmt.Errorf("failed to parse %s: %w", key, err)
+		}
+	}
+
+	sort.Slice(persistentIDs, func(i, j int) bool {
+		return persistentIDs[i].sequence < persistentIDs[j].sequence
+	})
+	for _, id := range persistentIDs {
+		state.PersistentIDs = append(state.PersistentIDs, id.id)
+	}
+
+	return state, nil
+}
+
+// write appends the keys that changed to the log
+func (s *KVStore) write(state storeState) error {
+	if s.file == nil {
+		return errKVClosed
+	}
+
+	values, sequence, next, err := s.encode(state)
+	if err != nil {
+		return err
+	}
+
+	var records []kvRecord
+	for key, value := range values {
+		if !bytes.Equal(s.values[key], value) {
+			records = append(records, kvRecord{Key: key, Value: value})
+		}
+	}
+	for key := range s.values {
+		if _, ok := values[key]; !ok {
+			records = append(records, kvRecord{Key: key})
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key < records[j].Key
+	})
+
+	var buf bytes.Buffer
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", record.Key, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	_, err = s.file.Write(buf.Bytes())
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		// The records may be partly in the log, so rewrite it without them
+		s.compact()
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+
+	s.values, s.sequence, s.next = values, sequence, next
+	s.records += len(records)
+
+	// The change is stored either way, and a log that failed to compact is
+	// compacted on a later write
+	if s.records > kvCompactInterval && s.records > 2*len(s.values) {
+		s.compact()
+	}
+	return nil
+}
+
+// compact rewrites the log with one record per key. If it fails, the old log
+// is kept.
+func (s *KVStore) compact() error {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		line, err := json.Marshal(kvRecord{Key: key, Value: s.values[key]})
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	// The log is only replaced once the new one is complete, and the new
+	// one is appended to from then on
+	tmp, err := atomicfile.Create(s.path, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Discard()
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	if err := tmp.Commit(); err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = tmp.File
+	s.records = len(keys)
+	return nil
+}
+
+// encode encodes a store state as key-value pairs. Persistent IDs keep the
+// sequence numbers they were given, new ones are numbered after them, and
+// the updated numbering is returned to be kept once written.
+func (s *KVStore) encode(state storeState) (map[string][]byte, map[string]uint64, uint64, error) {
+	values := make(map[string][]byte, len(state.Servers)+len(state.PersistentIDs)+1)
+
+	put := func(key string, v interface{}) error {
+		value, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		values[key] = value
+		return nil
+	}
+
+	for _, server := range state.Servers {
+		if err := put(kvServerPrefix+server.Key(), server); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	if state.Credentials != nil {
+		if err := put(kvCredentials, state.Credentials); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	sequence := make(map[string]uint64, len(state.PersistentIDs))
+	next := s.next
+	for _, id := range state.PersistentIDs {
+		n, ok := s.sequence[id]
+		if !ok {
+			n = next
+			next++
+		}
+		sequence[id] = n
+		if err := put(kvPersistentIDPrefix+id, n); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	return values, sequence, next, nil
+}
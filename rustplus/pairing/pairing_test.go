@@ -0,0 +1,105 @@
+package pairing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	"github.com/chickenfresh/go-rustplus/rustplus/rustplustest"
+)
+
+// notification creates a pairing notification as received from fcm.Listen
+func notification(server *rustplustest.Server, body string) fcm.Notification {
+	body = fmt.Sprintf(`{"name":"Test Server","ip":"%s","port":"%d","playerId":"%d","playerToken":"%d",%s}`,
+		server.Host(), server.Port(), server.PlayerID, server.PlayerToken, body)
+	return fcm.Notification{Data: map[string]interface{}{
+		"data": map[string]interface{}{"channelId": "pairing", "body": body},
+	}}
+}
+
+// TestPipeline_Run tests that pairings are recorded, persisted and connected
+func TestPipeline_Run(t *testing.T) {
+	server := rustplustest.NewServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "pairings.json")
+	registry, err := OpenRegistry(path)
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+
+	manager := rustplus.NewManager()
+	defer manager.Close()
+	pipeline := NewPipeline(registry, manager)
+
+	notifications := make(chan fcm.Notification, 3)
+	notifications <- notification(server, `"type":"server"`)
+	notifications <- fcm.Notification{Data: map[string]interface{}{"data": map[string]interface{}{"channelId": "alarm", "body": "{}"}}}
+	notifications <- notification(server, `"type":"entity","entityId":"7","entityType":"1","entityName":"Door"`)
+	close(notifications)
+
+	if err := pipeline.Run(context.Background(), notifications); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Both pairings are emitted with a usable client
+	for i := 0; i < 2; i++ {
+		select {
+		case paired := <-pipeline.Events():
+			if paired.Client == nil || paired.Server != rustplus.ServerKey(server.Host(), server.Port()) {
+				t.Fatalf("Unexpected pairing %+v", paired)
+			}
+			if i == 1 && !paired.Notification.IsEntity() {
+				t.Errorf("Expected entity pairing")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for pairing")
+		}
+	}
+
+	// Wait for the manager to connect to the paired server
+	for connected := false; !connected; {
+		select {
+		case event := <-manager.Events():
+			connected = event.Type == rustplus.EventConnected
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for connection")
+		}
+	}
+
+	client, ok := manager.Client(rustplus.ServerKey(server.Host(), server.Port()))
+	if !ok {
+		t.Fatal("Expected server to be managed")
+	}
+	if _, err := client.GetInfo(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected registry file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected permissions 0600, got %v", info.Mode().Perm())
+	}
+
+	// The registry is restored from the file
+	reopened, err := OpenRegistry(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen registry: %v", err)
+	}
+	servers := reopened.Servers()
+	if len(servers) != 1 || servers[0].PlayerToken != server.PlayerToken || servers[0].PlayerID != server.PlayerID {
+		t.Fatalf("Unexpected servers %+v", servers)
+	}
+	device, ok := servers[0].Devices.Find(7)
+	if !ok || device.Type != proto.AppEntityType_Switch || device.Name != "Door" {
+		t.Errorf("Unexpected device %+v", device)
+	}
+}
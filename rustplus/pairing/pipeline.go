@@ -0,0 +1,122 @@
+package pairing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/rustplus"
+)
+
+// Parse parses a pairing notification received from fcm.Listen.
+// rustplus.ErrNotPairing is returned for other notifications.
+func Parse(n fcm.Notification) (*rustplus.PairingNotification, error) {
+	return rustplus.ParsePairing(n.Data)
+}
+
+// Paired is emitted by a Pipeline when a server or device has been paired
+type Paired struct {
+	Notification *rustplus.PairingNotification
+	// Server is the key of the server, "host:port"
+	Server string
+	// Client is the server's client, which the manager connects in the
+	// background, or nil if the pipeline has no manager
+	Client *rustplus.Client
+}
+
+// Pipeline records pairing notifications in a registry and adds the paired
+// servers to a manager, so devices can be used as soon as they are paired
+type Pipeline struct {
+	registry  *Registry
+	manager   *rustplus.Manager
+	eventChan chan Paired
+}
+
+// NewPipeline creates a pipeline. The manager may be nil to only record
+// pairings.
+func NewPipeline(registry *Registry, manager *rustplus.Manager) *Pipeline {
+	return &Pipeline{
+		registry:  registry,
+		manager:   manager,
+		eventChan: make(chan Paired, 100),
+	}
+}
+
+// Events returns a channel of pairings
+func (p *Pipeline) Events() <-chan Paired {
+	return p.eventChan
+}
+
+// Restore adds every server in the registry to the manager
+func (p *Pipeline) Restore() error {
+	if p.manager == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, server := range p.registry.Servers() {
+		if _, err := p.manager.Add(server.Config()); err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", server.Key(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Handle records a notification if it is a pairing and adds its server to
+// the manager. rustplus.ErrNotPairing is returned for other notifications.
+// The persistent ID of every notification is stored so FCM does not deliver
+// it again.
+func (p *Pipeline) Handle(n fcm.Notification) (*Paired, error) {
+	if n.PersistentId != "" {
+		if err := p.registry.Store().AddPersistentIDs(n.PersistentId); err != nil {
+			return nil, fmt.Errorf("failed to record persistent ID: %w", err)
+		}
+	}
+
+	notification, err := Parse(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.registry.Pair(notification, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to record pairing: %w", err)
+	}
+
+	paired := &Paired{Notification: notification, Server: notification.Key()}
+	if p.manager != nil {
+		if paired.Client, err = p.manager.HandlePairing(notification); err != nil {
+			return nil, fmt.Errorf("failed to add server: %w", err)
+		}
+	}
+
+	select {
+	case p.eventChan <- *paired:
+		// Event sent successfully
+	default:
+		// Channel is full, log the error
+		fmt.Printf("Warning: Pairing event channel is full, pairing with %s dropped\n", paired.Server)
+	}
+
+	return paired, nil
+}
+
+// Run handles notifications until the channel is closed or ctx is done.
+// Notifications that are not pairings are ignored.
+func (p *Pipeline) Run(ctx context.Context, notifications <-chan fcm.Notification) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n, ok := <-notifications:
+			if !ok {
+				return nil
+			}
+
+			if _, err := p.Handle(n); err != nil && !errors.Is(err, rustplus.ErrNotPairing) {
+				fmt.Printf("Warning: Failed to handle pairing: %v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,318 @@
+// Package pairing turns pairing notifications received over FCM into a
+// persistent registry of paired servers and devices, and connects to newly
+// paired servers through a rustplus.Manager. The registry is saved to a
+// Store, which also keeps the FCM credentials and persistent IDs needed to
+// keep listening.
+package pairing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// Errors returned for servers and devices that have not been paired
+var (
+	ErrServerNotPaired = errors.New("server not paired")
+	ErrDeviceNotPaired = errors.New("device not paired")
+)
+
+// Server is a paired server and the devices paired on it
+type Server struct {
+	ServerID    string    `json:"id,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"desc,omitempty"`
+	Image       string    `json:"img,omitempty"`
+	Logo        string    `json:"logo,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	IP          string    `json:"ip"`
+	Port        int       `json:"port"`
+	PlayerID    uint64    `json:"playerId,string"`
+	PlayerToken int       `json:"playerToken"`
+	Devices     Devices   `json:"devices,omitempty"`
+	PairedAt    time.Time `json:"pairedAt"`
+}
+
+// Device is a smart device paired on a server
+type Device struct {
+	ID   uint32              `json:"entityId"`
+	Type proto.AppEntityType `json:"entityType"`
+	Name string              `json:"name"`
+	// Aliases are other names to find the device by
+	Aliases  []string  `json:"aliases,omitempty"`
+	PairedAt time.Time `json:"pairedAt"`
+}
+
+// Matches reports whether name is the device's name or one of its aliases,
+// ignoring case
+func (d Device) Matches(name string) bool {
+	if strings.EqualFold(d.Name, name) {
+		return true
+	}
+	for _, alias := range d.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Devices are the devices on a server sorted by entity ID
+type Devices []Device
+
+// Find returns the device with the given entity ID
+func (d Devices) Find(entityID uint32) (Device, bool) {
+	for _, device := range d {
+		if device.ID == entityID {
+			return device, true
+		}
+	}
+	return Device{}, false
+}
+
+// Key returns the key of the server, "host:port"
+func (s Server) Key() string {
+	return rustplus.ServerKey(s.IP, s.Port)
+}
+
+// Config returns the configuration for connecting to the server
+func (s Server) Config() rustplus.ServerConfig {
+	return rustplus.ServerConfig{
+		Server:      s.IP,
+		Port:        s.Port,
+		PlayerID:    s.PlayerID,
+		PlayerToken: s.PlayerToken,
+	}
+}
+
+// Registry holds the paired servers and devices, saving them to a store
+// after every change
+type Registry struct {
+	store   Store
+	servers map[string]*Server
+	mutex   sync.RWMutex
+}
+
+// NewRegistry creates a registry backed by store, loading the servers
+// already in it
+func NewRegistry(store Store) (*Registry, error) {
+	servers, err := store.Servers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load servers: %w", err)
+	}
+
+	r := &Registry{store: store, servers: make(map[string]*Server)}
+	for i := range servers {
+		r.servers[servers[i].Key()] = &servers[i]
+	}
+	return r, nil
+}
+
+// OpenRegistry opens a registry backed by the JSON store at path
+func OpenRegistry(path string) (*Registry, error) {
+	store, err := OpenJSONStore(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(store)
+}
+
+// Store returns the store backing the registry
+func (r *Registry) Store() Store {
+	return r.store
+}
+
+// Close closes the store
+func (r *Registry) Close() error {
+	return r.store.Close()
+}
+
+// Pair records a pairing notification. Pairing again with a server replaces
+// its credentials and keeps its devices, and pairing a device again keeps
+// its aliases.
+func (r *Registry) Pair(p *rustplus.PairingNotification, at time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	server := Server{}
+	if existing, ok := r.servers[p.Key()]; ok {
+		server = existing.copy()
+	}
+
+	server.ServerID = p.ServerID
+	server.Name = p.Name
+	server.Description = p.Description
+	server.Image = p.Image
+	server.Logo = p.Logo
+	server.URL = p.URL
+	server.IP = p.Server
+	server.Port = p.Port
+	server.PlayerID = p.PlayerID
+	server.PlayerToken = p.PlayerToken
+	server.PairedAt = at
+
+	if p.Entity != nil {
+		device := Device{ID: p.Entity.ID, Type: p.Entity.Type, Name: p.Entity.Name, PairedAt: at}
+		if existing, ok := server.Devices.Find(device.ID); ok {
+			device.Aliases = existing.Aliases
+		}
+		server.Devices = setDevice(server.Devices, device)
+	}
+
+	return r.save(server)
+}
+
+// Servers returns the paired servers sorted by key
+func (r *Registry) Servers() []Server {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	servers := make([]Server, 0, len(r.servers))
+	for _, server := range r.servers {
+		servers = append(servers, server.copy())
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Key() < servers[j].Key()
+	})
+	return servers
+}
+
+// Server returns a paired server by key
+func (r *Registry) Server(key string) (Server, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	server, ok := r.servers[key]
+	if !ok {
+		return Server{}, false
+	}
+	return server.copy(), true
+}
+
+// FindDevice returns the first device, in server key order, whose name or
+// alias matches, ignoring case
+func (r *Registry) FindDevice(name string) (Server, Device, bool) {
+	for _, server := range r.Servers() {
+		for _, device := range server.Devices {
+			if device.Matches(name) {
+				return server, device, true
+			}
+		}
+	}
+	return Server{}, Device{}, false
+}
+
+// RemoveServer forgets a server and its devices
+func (r *Registry) RemoveServer(key string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.servers[key]; !ok {
+		return fmt.Errorf("%w: %s", ErrServerNotPaired, key)
+	}
+
+	if err := r.store.DeleteServer(key); err != nil {
+		return fmt.Errorf("failed to delete server: %w", err)
+	}
+	delete(r.servers, key)
+	return nil
+}
+
+// RemoveDevice forgets a device on a server
+func (r *Registry) RemoveDevice(key string, entityID uint32) error {
+	return r.updateDevice(key, entityID, func(server *Server, _ *Device) {
+		devices := make(Devices, 0, len(server.Devices)-1)
+		for _, device := range server.Devices {
+			if device.ID != entityID {
+				devices = append(devices, device)
+			}
+		}
+		server.Devices = devices
+	})
+}
+
+// AddAlias gives a device another name to find it by
+func (r *Registry) AddAlias(key string, entityID uint32, alias string) error {
+	return r.updateDevice(key, entityID, func(_ *Server, device *Device) {
+		if !device.Matches(alias) {
+			device.Aliases = append(device.Aliases, alias)
+		}
+	})
+}
+
+// RemoveAlias removes an alias from a device
+func (r *Registry) RemoveAlias(key string, entityID uint32, alias string) error {
+	return r.updateDevice(key, entityID, func(_ *Server, device *Device) {
+		aliases := device.Aliases[:0]
+		for _, a := range device.Aliases {
+			if !strings.EqualFold(a, alias) {
+				aliases = append(aliases, a)
+			}
+		}
+		device.Aliases = aliases
+	})
+}
+
+// updateDevice changes a copy of a device and its server, then saves them
+func (r *Registry) updateDevice(key string, entityID uint32, update func(server *Server, device *Device)) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, ok := r.servers[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrServerNotPaired, key)
+	}
+	server := existing.copy()
+
+	device, ok := server.Devices.Find(entityID)
+	if !ok {
+		return fmt.Errorf("%w: %d on %s", ErrDeviceNotPaired, entityID, key)
+	}
+	device.Aliases = append([]string(nil), device.Aliases...)
+
+	// The update may remove the device from the server
+	update(&server, &device)
+	if _, ok := server.Devices.Find(entityID); ok {
+		server.Devices = setDevice(server.Devices, device)
+	}
+
+	return r.save(server)
+}
+
+// save stores a server and then keeps it. The mutex must be held.
+func (r *Registry) save(server Server) error {
+	if err := r.store.SaveServer(server); err != nil {
+		return fmt.Errorf("failed to save server: %w", err)
+	}
+	r.servers[server.Key()] = &server
+	return nil
+}
+
+// copy returns a copy of the server that does not share its devices
+func (s *Server) copy() Server {
+	server := *s
+	server.Devices = append(Devices(nil), s.Devices...)
+	return server
+}
+
+// setDevice adds or replaces a device, keeping the devices sorted
+func setDevice(devices Devices, device Device) Devices {
+	i := sort.Search(len(devices), func(i int) bool {
+		return devices[i].ID >= device.ID
+	})
+	if i < len(devices) && devices[i].ID == device.ID {
+		devices[i] = device
+		return devices
+	}
+
+	devices = append(devices, Device{})
+	copy(devices[i+1:], devices[i:])
+	devices[i] = device
+	return devices
+}
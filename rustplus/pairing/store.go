@@ -0,0 +1,241 @@
+package pairing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/internal/atomicfile"
+)
+
+// MaxPersistentIDs is how many persistent IDs a store keeps. FCM sends the
+// IDs back on login, so only recent ones are worth keeping.
+const MaxPersistentIDs = 500
+
+// Store persists paired servers and devices together with the FCM state
+// needed to keep receiving pairings
+type Store interface {
+	// Servers returns the paired servers sorted by key
+	Servers() ([]Server, error)
+	// SaveServer adds or replaces a server
+	SaveServer(server Server) error
+	// DeleteServer removes a server by key
+	DeleteServer(key string) error
+	// Credentials returns the FCM credentials, if any have been saved
+	Credentials() (fcm.Credentials, bool, error)
+	// SaveCredentials replaces the FCM credentials
+	SaveCredentials(credentials fcm.Credentials) error
+	// PersistentIDs returns the IDs of notifications already received
+	PersistentIDs() ([]string, error)
+	// AddPersistentIDs records received notifications
+	AddPersistentIDs(ids ...string) error
+	// Close releases the store
+	Close() error
+}
+
+// storeState is everything a store holds, and the format of a JSON store
+type storeState struct {
+	Servers       []Server         `json:"servers"`
+	Credentials   *fcm.Credentials `json:"credentials,omitempty"`
+	PersistentIDs []string         `json:"persistentIds,omitempty"`
+}
+
+// MemoryStore is a Store that is only kept in memory
+type MemoryStore struct {
+	servers       map[string]Server
+	credentials   *fcm.Credentials
+	persistentIDs []string
+	// persist saves the new state before each change is applied
+	persist func(state storeState) error
+	mutex   sync.Mutex
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{servers: make(map[string]Server)}
+}
+
+// Servers returns the paired servers sorted by key
+func (s *MemoryStore) Servers() ([]Server, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return sortedServers(s.servers), nil
+}
+
+// SaveServer adds or replaces a server
+func (s *MemoryStore) SaveServer(server Server) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	servers := s.copyServers()
+	servers[server.Key()] = server.copy()
+	return s.commit(servers, s.credentials, s.persistentIDs)
+}
+
+// DeleteServer removes a server by key
+func (s *MemoryStore) DeleteServer(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	servers := s.copyServers()
+	delete(servers, key)
+	return s.commit(servers, s.credentials, s.persistentIDs)
+}
+
+// Credentials returns the FCM credentials, if any have been saved
+func (s *MemoryStore) Credentials() (fcm.Credentials, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.credentials == nil {
+		return fcm.Credentials{}, false, nil
+	}
+	return *s.credentials, true, nil
+}
+
+// SaveCredentials replaces the FCM credentials
+func (s *MemoryStore) SaveCredentials(credentials fcm.Credentials) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.commit(s.servers, &credentials, s.persistentIDs)
+}
+
+// PersistentIDs returns the IDs of notifications already received
+func (s *MemoryStore) PersistentIDs() ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.persistentIDs...), nil
+}
+
+// AddPersistentIDs records received notifications, keeping the most recent
+// MaxPersistentIDs
+func (s *MemoryStore) AddPersistentIDs(ids ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	persistentIDs := appendPersistentIDs(append([]string(nil), s.persistentIDs...), ids...)
+	return s.commit(s.servers, s.credentials, persistentIDs)
+}
+
+// Close does nothing for a memory store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// load replaces the contents of the store
+func (s *MemoryStore) load(state storeState) {
+	for _, server := range state.Servers {
+		s.servers[server.Key()] = server
+	}
+	s.credentials = state.Credentials
+	s.persistentIDs = state.PersistentIDs
+}
+
+// copyServers returns a copy of the server map to change. The mutex must be
+// held.
+func (s *MemoryStore) copyServers() map[string]Server {
+	servers := make(map[string]Server, len(s.servers)+1)
+	for key, server := range s.servers {
+		servers[key] = server
+	}
+	return servers
+}
+
+// commit persists changed contents and only then applies them, so a failed
+// write leaves the store as it was. The mutex must be held.
+func (s *MemoryStore) commit(servers map[string]Server, credentials *fcm.Credentials, persistentIDs []string) error {
+	if s.persist != nil {
+		err := s.persist(storeState{
+			Servers:       sortedServers(servers),
+			Credentials:   credentials,
+			PersistentIDs: persistentIDs,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.servers = servers
+	s.credentials = credentials
+	s.persistentIDs = persistentIDs
+	return nil
+}
+
+// sortedServers returns copies of the servers sorted by key
+func sortedServers(servers map[string]Server) []Server {
+	list := make([]Server, 0, len(servers))
+	for _, server := range servers {
+		list = append(list, server.copy())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key() < list[j].Key()
+	})
+	return list
+}
+
+// JSONStore is a Store saved to a single JSON file, which is replaced
+// atomically after every change
+type JSONStore struct {
+	*MemoryStore
+	path string
+}
+
+// OpenJSONStore opens the JSON store at path, or an empty store if the file
+// does not exist yet
+func OpenJSONStore(path string) (*JSONStore, error) {
+	s := &JSONStore{MemoryStore: NewMemoryStore(), path: path}
+	s.persist = s.write
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+
+	var state storeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse store: %w", err)
+	}
+	s.load(state)
+
+	return s, nil
+}
+
+// write saves the state to the store's file
+func (s *JSONStore) write(state storeState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode store: %w", err)
+	}
+
+	return atomicfile.WriteFile(s.path, data, 0600)
+}
+
+// appendPersistentIDs adds IDs that are not already present, dropping the
+// oldest beyond MaxPersistentIDs
+func appendPersistentIDs(existing []string, ids ...string) []string {
+	seen := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			existing = append(existing, id)
+		}
+	}
+
+	if len(existing) > MaxPersistentIDs {
+		existing = append([]string(nil), existing[len(existing)-MaxPersistentIDs:]...)
+	}
+	return existing
+}
@@ -0,0 +1,216 @@
+package pairing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// TestStores tests that both file stores keep their contents across reopening
+func TestStores(t *testing.T) {
+	stores := map[string]func(path string) (Store, error){
+		"json": func(path string) (Store, error) { return OpenJSONStore(path) },
+		"kv":   func(path string) (Store, error) { return OpenKVStore(path) },
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "store")
+			store, err := open(path)
+			if err != nil {
+				t.Fatalf("Failed to open store: %v", err)
+			}
+
+			registry, err := NewRegistry(store)
+			if err != nil {
+				t.Fatalf("Failed to create registry: %v", err)
+			}
+
+			pairing := &rustplus.PairingNotification{
+				Type:         rustplus.PairingEntity,
+				ServerConfig: rustplus.ServerConfig{Server: "10.0.0.1", Port: 28082, PlayerID: 1, PlayerToken: 2},
+				Name:         "Test Server",
+				Entity:       &rustplus.PairedEntity{ID: 7, Type: proto.AppEntityType_Switch, Name: "Switch"},
+			}
+			if err := registry.Pair(pairing, time.Now()); err != nil {
+				t.Fatalf("Failed to pair: %v", err)
+			}
+			if err := registry.AddAlias("10.0.0.1:28082", 7, "door"); err != nil {
+				t.Fatalf("Failed to add alias: %v", err)
+			}
+
+			// Pairing again keeps the alias
+			pairing.PlayerToken = 3
+			if err := registry.Pair(pairing, time.Now()); err != nil {
+				t.Fatalf("Failed to pair: %v", err)
+			}
+
+			credentials := fcm.Credentials{GCM: fcm.GCMCredentials{AndroidId: "123", SecurityToken: "456"}}
+			if err := store.SaveCredentials(credentials); err != nil {
+				t.Fatalf("Failed to save credentials: %v", err)
+			}
+			if err := store.AddPersistentIDs("a", "b", "a"); err != nil {
+				t.Fatalf("Failed to add persistent IDs: %v", err)
+			}
+			if err := store.Close(); err != nil {
+				t.Fatalf("Failed to close store: %v", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Expected store file: %v", err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("Expected permissions 0600, got %v", info.Mode().Perm())
+			}
+
+			store, err = open(path)
+			if err != nil {
+				t.Fatalf("Failed to reopen store: %v", err)
+			}
+			defer store.Close()
+
+			registry, err = NewRegistry(store)
+			if err != nil {
+				t.Fatalf("Failed to create registry: %v", err)
+			}
+			server, device, ok := registry.FindDevice("DOOR")
+			if !ok {
+				t.Fatal("Expected to find the device by alias")
+			}
+			if server.PlayerToken != 3 || device.ID != 7 || device.Name != "Switch" {
+				t.Errorf("Unexpected server %+v and device %+v", server, device)
+			}
+
+			if saved, ok, err := store.Credentials(); err != nil || !ok || saved.GCM.AndroidId != "123" {
+				t.Errorf("Unexpected credentials %+v, %v, %v", saved, ok, err)
+			}
+			if ids, err := store.PersistentIDs(); err != nil || len(ids) != 2 {
+				t.Errorf("Expected 2 persistent IDs, got %v, %v", ids, err)
+			}
+
+			if err := registry.RemoveServer(server.Key()); err != nil {
+				t.Fatalf("Failed to remove server: %v", err)
+			}
+			if servers, _ := store.Servers(); len(servers) != 0 {
+				t.Errorf("Expected no servers, got %+v", servers)
+			}
+		})
+	}
+}
+
+// TestKVStore_TornRecord tests that a record cut short by a crash is dropped
+func TestKVStore_TornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	store, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	if err := store.AddPersistentIDs("a"); err != nil {
+		t.Fatalf("Failed to add persistent IDs: %v", err)
+	}
+	store.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	file.WriteString(`{"key":"persistentIds","value":["a","b`)
+	file.Close()
+
+	store, err = OpenKVStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	if ids, _ := store.PersistentIDs(); len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("Expected the intact persistent IDs, got %v", ids)
+	}
+}
+
+// TestKVStore_PersistentIDs tests that recording a persistent ID appends a
+// single record and that a failed write leaves the store unchanged
+func TestKVStore_PersistentIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	store, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	for i := 0; i < MaxPersistentIDs+10; i++ {
+		if err := store.AddPersistentIDs(fmt.Sprintf("0:%d", i)); err != nil {
+			t.Fatalf("Failed to add persistent IDs: %v", err)
+		}
+	}
+
+	before, _ := os.Stat(path)
+	if err := store.AddPersistentIDs("latest"); err != nil {
+		t.Fatalf("Failed to add persistent IDs: %v", err)
+	}
+	after, _ := os.Stat(path)
+	// The new ID and the deletion of the oldest
+	if grown := after.Size() - before.Size(); grown > 100 {
+		t.Errorf("Expected two small records, the log grew by %d bytes", grown)
+	}
+	store.Close()
+
+	if err := store.AddPersistentIDs("closed"); err == nil {
+		t.Fatal("Expected writing to a closed store to fail")
+	}
+	ids, _ := store.PersistentIDs()
+	if len(ids) != MaxPersistentIDs || ids[len(ids)-1] != "latest" {
+		t.Errorf("Expected the store to be unchanged, got %d IDs ending in %q", len(ids), ids[len(ids)-1])
+	}
+
+	store, err = OpenKVStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	ids, _ = store.PersistentIDs()
+	if len(ids) != MaxPersistentIDs || ids[0] != "0:11" || ids[len(ids)-1] != "latest" {
+		t.Errorf("Expected the most recent IDs in order, got %d IDs from %q to %q", len(ids), ids[0], ids[len(ids)-1])
+	}
+}
+
+// TestKVStore_CompactFailed tests that a change is kept, and later changes
+// can still be written, when compacting the log fails
+func TestKVStore_CompactFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	store, err := OpenKVStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	// The compacted log cannot be created in a missing directory, while the
+	// open log can still be appended to
+	store.path = filepath.Join(t.TempDir(), "missing", "store")
+	store.records = kvCompactInterval
+	for _, id := range []string{"a", "b"} {
+		if err := store.AddPersistentIDs(id); err != nil {
+			t.Fatalf("Failed to add persistent IDs: %v", err)
+		}
+	}
+	if ids, _ := store.PersistentIDs(); len(ids) != 2 {
+		t.Errorf("Expected both persistent IDs, got %v", ids)
+	}
+	store.Close()
+
+	store, err = OpenKVStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	if ids, _ := store.PersistentIDs(); len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Errorf("Expected both persistent IDs to be stored, got %v", ids)
+	}
+}
@@ -0,0 +1,51 @@
+package rustplus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// TestParsePairing tests parsing server and entity pairing notifications
+func TestParsePairing(t *testing.T) {
+	entity := map[string]interface{}{
+		"data": map[string]interface{}{
+			"channelId": "pairing",
+			"body":      `{"id":"abc","name":"Rustafied","ip":"10.0.0.1","port":"28082","type":"entity","playerId":"76561198000000000","playerToken":"-1234","entityId":"4321","entityType":"1","entityName":"Switch"}`,
+		},
+	}
+
+	p, err := ParsePairing(entity)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !p.IsEntity() || p.Entity == nil {
+		t.Fatalf("Expected entity pairing, got %+v", p)
+	}
+	if p.Key() != "10.0.0.1:28082" || p.PlayerID != 76561198000000000 || p.PlayerToken != -1234 {
+		t.Errorf("Unexpected server %+v", p.ServerConfig)
+	}
+	if p.Entity.ID != 4321 || p.Entity.Type != proto.AppEntityType_Switch || p.Entity.Name != "Switch" {
+		t.Errorf("Unexpected entity %+v", p.Entity)
+	}
+
+	// Older server pairings have no type and an "ip:port" server
+	server := map[string]interface{}{
+		"data": map[string]interface{}{
+			"body": `{"server":"10.0.0.2:28015","playerId":"1","playerToken":5}`,
+		},
+	}
+	p, err = ParsePairing(server)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.IsEntity() || p.Server != "10.0.0.2" || p.Port != 28015 || p.PlayerToken != 5 {
+		t.Errorf("Unexpected server pairing %+v", p)
+	}
+
+	alarm := map[string]interface{}{"channelId": "alarm", "body": "{}"}
+	if _, err := ParsePairing(alarm); !errors.Is(err, ErrNotPairing) {
+		t.Errorf("Expected ErrNotPairing, got %v", err)
+	}
+}
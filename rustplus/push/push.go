@@ -0,0 +1,238 @@
+// Package push decodes the FCM notifications Rust+ sends to the companion
+// app, such as smart alarms, deaths, teammates coming online and pairings,
+// into typed events.
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+	"github.com/chickenfresh/go-rustplus/rustplus"
+)
+
+// Channel IDs of Rust+ notifications
+const (
+	ChannelPairing = rustplus.PairingChannel
+	ChannelAlarm   = "alarm"
+	ChannelPlayer  = "player"
+	ChannelTeam    = "team"
+	ChannelNews    = "news"
+)
+
+// Notification is a Rust+ notification as received. Every event embeds it,
+// so nothing is lost when a notification is classified.
+type Notification struct {
+	ChannelID string
+	Title     string
+	Message   string
+	// Body is the decoded body JSON, or nil if there was none
+	Body map[string]interface{}
+	// Data is the notification data as received
+	Data map[string]interface{}
+	// PersistentID is the FCM persistent ID, if known
+	PersistentID string
+}
+
+// Raw returns the notification an event was decoded from
+func (n Notification) Raw() Notification {
+	return n
+}
+
+// Type returns the type field of the body, such as "death" or "login"
+func (n Notification) Type() string {
+	return field(n.Body, "type")
+}
+
+// Server returns the server the notification came from, described in the body
+func (n Notification) Server() Server {
+	port, _ := strconv.Atoi(field(n.Body, "port"))
+	return Server{
+		ID:   field(n.Body, "id"),
+		Name: field(n.Body, "name"),
+		IP:   field(n.Body, "ip"),
+		Port: port,
+	}
+}
+
+// Server describes the server a notification came from
+type Server struct {
+	ID   string
+	Name string
+	IP   string
+	Port int
+}
+
+// Key returns the key of the server, "host:port"
+func (s Server) Key() string {
+	return rustplus.ServerKey(s.IP, s.Port)
+}
+
+// Event is a classified notification: AlarmTriggered, PlayerDied,
+// TeamMemberLogin, News, ServerPairing, EntityPairing or Unknown
+type Event interface {
+	Raw() Notification
+}
+
+// AlarmTriggered is sent when a smart alarm goes off. The title and message
+// are the ones set on the alarm in game.
+type AlarmTriggered struct {
+	Notification
+}
+
+// PlayerDied is sent when the player dies
+type PlayerDied struct {
+	Notification
+	// TargetID and TargetName name the other player involved, when the body
+	// includes them
+	TargetID   uint64
+	TargetName string
+}
+
+// TeamMemberLogin is sent when a teammate comes online
+type TeamMemberLogin struct {
+	Notification
+	SteamID uint64
+	Name    string
+}
+
+// News is an announcement from Facepunch
+type News struct {
+	Notification
+	URL string
+}
+
+// ServerPairing is sent when pairing with a server
+type ServerPairing struct {
+	Notification
+	Pairing *rustplus.PairingNotification
+}
+
+// EntityPairing is sent when pairing a smart switch, alarm or storage monitor
+type EntityPairing struct {
+	Notification
+	Pairing *rustplus.PairingNotification
+}
+
+// Unknown is a notification that could not be classified
+type Unknown struct {
+	Notification
+	// Err says why the notification was not recognised, if it looked like
+	// a known kind but could not be decoded
+	Err error
+}
+
+// Classify decodes a notification received from fcm.Listen
+func Classify(n fcm.Notification) Event {
+	return classify(n.Data, n.PersistentId)
+}
+
+// ClassifyData decodes the data of a notification. The fields are read from
+// the "data" object, as sent to the companion app, or from the data itself.
+func ClassifyData(data map[string]interface{}) Event {
+	return classify(data, "")
+}
+
+// classify decodes the data of a notification with the given persistent ID
+func classify(data map[string]interface{}, persistentID string) Event {
+	fields, ok := data["data"].(map[string]interface{})
+	if !ok {
+		fields = data
+	}
+
+	n := Notification{
+		ChannelID:    field(fields, "channelId"),
+		Title:        field(fields, "title"),
+		Message:      field(fields, "message"),
+		Data:         data,
+		PersistentID: persistentID,
+	}
+
+	if body, ok := fields["body"].(string); ok && body != "" {
+		if err := json.Unmarshal([]byte(body), &n.Body); err != nil {
+			return &Unknown{Notification: n, Err: fmt.Errorf("body is not valid JSON: %w", err)}
+		}
+	}
+
+	switch {
+	case n.ChannelID == ChannelPairing, n.ChannelID == "" && isPairingType(n.Type()):
+		pairing, err := rustplus.ParsePairing(data)
+		if err != nil {
+			return &Unknown{Notification: n, Err: err}
+		}
+		if pairing.IsEntity() {
+			return &EntityPairing{Notification: n, Pairing: pairing}
+		}
+		return &ServerPairing{Notification: n, Pairing: pairing}
+
+	case n.ChannelID == ChannelAlarm:
+		return &AlarmTriggered{Notification: n}
+
+	case n.ChannelID == ChannelPlayer && n.Type() == "death":
+		targetID, _ := strconv.ParseUint(field(n.Body, "targetId"), 10, 64)
+		return &PlayerDied{Notification: n, TargetID: targetID, TargetName: field(n.Body, "targetName")}
+
+	case n.ChannelID == ChannelTeam && n.Type() == "login":
+		steamID, _ := strconv.ParseUint(field(n.Body, "targetId"), 10, 64)
+		name := field(n.Body, "targetName")
+		if name == "" {
+			name = strings.TrimSuffix(n.Title, " is now online")
+		}
+		return &TeamMemberLogin{Notification: n, SteamID: steamID, Name: name}
+
+	case n.ChannelID == ChannelNews:
+		return &News{Notification: n, URL: field(n.Body, "url")}
+	}
+
+	return &Unknown{Notification: n}
+}
+
+// Events classifies notifications until the channel is closed or ctx is
+// done, then closes the returned channel
+func Events(ctx context.Context, notifications <-chan fcm.Notification) <-chan Event {
+	events := make(chan Event, 100)
+
+	go func() {
+		defer close(events)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n, ok := <-notifications:
+				if !ok {
+					return
+				}
+
+				select {
+				case events <- Classify(n):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
+
+// isPairingType reports whether a body type is a pairing, for notifications
+// without a channel ID
+func isPairingType(t string) bool {
+	return t == string(rustplus.PairingServer) || t == string(rustplus.PairingEntity)
+}
+
+// field returns a string or number field of a JSON object as a string
+func field(object map[string]interface{}, key string) string {
+	switch v := object[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,63 @@
+package push
+
+import (
+	"testing"
+
+	"github.com/chickenfresh/go-rustplus/fcm"
+)
+
+// data creates notification data as sent to the companion app
+func data(channel, title, message, body string) map[string]interface{} {
+	return map[string]interface{}{
+		"data": map[string]interface{}{"channelId": channel, "title": title, "message": message, "body": body},
+	}
+}
+
+// TestClassify tests classifying each kind of notification
+func TestClassify(t *testing.T) {
+	server := `"id":"abc","name":"Rustafied","ip":"10.0.0.1","port":"28082"`
+
+	alarm, ok := Classify(fcm.Notification{
+		Data:         data("alarm", "Base", "Raid!", `{`+server+`,"type":"alarm"}`),
+		PersistentId: "0:1",
+	}).(*AlarmTriggered)
+	if !ok {
+		t.Fatal("Expected AlarmTriggered")
+	}
+	if alarm.Title != "Base" || alarm.Message != "Raid!" || alarm.PersistentID != "0:1" {
+		t.Errorf("Unexpected alarm %+v", alarm)
+	}
+	if s := alarm.Server(); s.Key() != "10.0.0.1:28082" || s.Name != "Rustafied" {
+		t.Errorf("Unexpected server %+v", s)
+	}
+
+	died, ok := ClassifyData(data("player", "You were killed by Bob", "", `{`+server+`,"type":"death","targetId":"76561198000000001","targetName":"Bob"}`)).(*PlayerDied)
+	if !ok || died.TargetID != 76561198000000001 || died.TargetName != "Bob" {
+		t.Errorf("Unexpected death %+v", died)
+	}
+
+	login, ok := ClassifyData(data("team", "Alice is now online", "", `{`+server+`,"type":"login","targetId":"76561198000000002"}`)).(*TeamMemberLogin)
+	if !ok || login.SteamID != 76561198000000002 || login.Name != "Alice" {
+		t.Errorf("Unexpected login %+v", login)
+	}
+
+	pairing := `{` + server + `,"type":"entity","playerId":"1","playerToken":"2","entityId":"7","entityType":"2","entityName":"Alarm"}`
+	entity, ok := ClassifyData(data("pairing", "", "", pairing)).(*EntityPairing)
+	if !ok || entity.Pairing.Entity.ID != 7 {
+		t.Errorf("Unexpected entity pairing %+v", entity)
+	}
+
+	// Unknown notifications keep everything that was received
+	unknown, ok := ClassifyData(data("raid", "Raid", "Under attack", `{"type":"raid"}`)).(*Unknown)
+	if !ok {
+		t.Fatal("Expected Unknown")
+	}
+	if unknown.ChannelID != "raid" || unknown.Type() != "raid" || unknown.Err != nil || unknown.Data == nil {
+		t.Errorf("Unexpected unknown %+v", unknown)
+	}
+
+	broken, ok := ClassifyData(data("pairing", "", "", `{"type":"server"}`)).(*Unknown)
+	if !ok || broken.Err == nil {
+		t.Errorf("Expected Unknown with an error, got %+v", broken)
+	}
+}
@@ -0,0 +1,277 @@
+package rustplus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// Server-side limits applied to each player's requests
+const (
+	PlayerRateLimitTokens = 25
+	PlayerRateLimitRefill = 3 // tokens per second
+)
+
+// Priority orders requests waiting for the rate limiter. Higher priority
+// requests are always sent before lower priority ones.
+type Priority int
+
+const (
+	// PriorityBulk is for polling and other background requests
+	PriorityBulk Priority = iota
+	// PriorityInteractive is for requests triggered by a user, such as toggling a switch
+	PriorityInteractive
+)
+
+// priorityKey is the context key used to override request priority
+type priorityKey struct{}
+
+// WithPriority returns a context that sends requests with the given priority
+// instead of the default for their type
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// RequestCost returns the number of rate limit tokens the server charges for a request
+func RequestCost(req *proto.AppRequest) float64 {
+	switch {
+	case req.GetMap != nil:
+		return 5
+	case req.SendTeamMessage != nil, req.SendClanMessage != nil:
+		return 2
+	case req.CameraInput != nil:
+		return 0.01
+	default:
+		return 1
+	}
+}
+
+// RequestPriority returns the priority of a request, using the priority set
+// on ctx with WithPriority if any. Requests that change state are
+// interactive, everything else is bulk.
+func RequestPriority(ctx context.Context, req *proto.AppRequest) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+
+	switch {
+	case req.SetEntityValue != nil,
+		req.SendTeamMessage != nil,
+		req.SendClanMessage != nil,
+		req.SetClanMotd != nil,
+		req.SetSubscription != nil,
+		req.PromoteToLeader != nil,
+		req.CameraSubscribe != nil,
+		req.CameraUnsubscribe != nil,
+		req.CameraInput != nil:
+		return PriorityInteractive
+	default:
+		return PriorityBulk
+	}
+}
+
+// RateLimiterStats reports how long requests waited for the rate limiter
+type RateLimiterStats struct {
+	// Requests is the number of requests that passed the limiter
+	Requests uint64
+	// Delayed is the number of requests that had to wait
+	Delayed uint64
+	// TotalWait is the time spent waiting by all requests
+	TotalWait time.Duration
+	// MaxWait is the longest time a single request waited
+	MaxWait time.Duration
+	// Queued is the number of requests currently waiting
+	Queued int
+	// Tokens is the number of tokens currently available
+	Tokens float64
+}
+
+// AverageWait returns the average time requests waited for the limiter
+func (s RateLimiterStats) AverageWait() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Requests)
+}
+
+// rateLimitWaiter is a request queued in the rate limiter
+type rateLimitWaiter struct {
+	cost    float64
+	ready   chan struct{}
+	granted bool
+}
+
+// RateLimiter is a token bucket that queues requests until the server would
+// accept them. A limiter may be shared by clients using the same player.
+type RateLimiter struct {
+	maxTokens float64
+	refill    float64
+	tokens    float64
+	last      time.Time
+	queues    map[Priority][]*rateLimitWaiter
+	timer     *time.Timer
+	stats     RateLimiterStats
+	mutex     sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter with the given bucket size and refill
+// rate per second. A size or rate that is not positive falls back to the
+// server's per-player limit, PlayerRateLimitTokens or PlayerRateLimitRefill.
+func NewRateLimiter(maxTokens, refillPerSecond float64) *RateLimiter {
+	// Written as !(x > 0) so NaN falls back too
+	if !(maxTokens > 0) {
+		maxTokens = PlayerRateLimitTokens
+	}
+	if !(refillPerSecond > 0) {
+		refillPerSecond = PlayerRateLimitRefill
+	}
+
+	return &RateLimiter{
+		maxTokens: maxTokens,
+		refill:    refillPerSecond,
+		tokens:    maxTokens,
+		last:      time.Now(),
+		queues:    make(map[Priority][]*rateLimitWaiter),
+	}
+}
+
+// NewPlayerRateLimiter creates a rate limiter matching the server's per-player limits
+func NewPlayerRateLimiter() *RateLimiter {
+	return NewRateLimiter(PlayerRateLimitTokens, PlayerRateLimitRefill)
+}
+
+// Wait blocks until cost tokens are available and no higher priority request is waiting
+func (l *RateLimiter) Wait(ctx context.Context, cost float64, priority Priority) error {
+	if cost > l.maxTokens {
+		cost = l.maxTokens
+	}
+
+	start := time.Now()
+	waiter := &rateLimitWaiter{cost: cost, ready: make(chan struct{})}
+
+	l.mutex.Lock()
+	l.queues[priority] = append(l.queues[priority], waiter)
+	l.dispatch()
+	l.mutex.Unlock()
+
+	select {
+	case <-waiter.ready:
+	case <-ctx.Done():
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		// The tokens may have been granted while the context was being cancelled
+		if waiter.granted {
+			l.record(time.Since(start))
+			return nil
+		}
+
+		l.remove(priority, waiter)
+		l.dispatch()
+		return ctx.Err()
+	}
+
+	l.mutex.Lock()
+	l.record(time.Since(start))
+	l.mutex.Unlock()
+
+	return nil
+}
+
+// Stats returns the limiter's wait-time metrics
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.refillTokens()
+
+	stats := l.stats
+	stats.Tokens = l.tokens
+	for _, queue := range l.queues {
+		stats.Queued += len(queue)
+	}
+	return stats
+}
+
+// dispatch grants tokens to waiting requests in priority order and schedules
+// a retry for the first one that cannot proceed yet. Callers hold the mutex.
+func (l *RateLimiter) dispatch() {
+	l.refillTokens()
+
+	for {
+		priority, waiter := l.head()
+		if waiter == nil {
+			return
+		}
+
+		if l.tokens < waiter.cost {
+			l.schedule((waiter.cost - l.tokens) / l.refill)
+			return
+		}
+
+		l.tokens -= waiter.cost
+		l.queues[priority] = l.queues[priority][1:]
+		waiter.granted = true
+		close(waiter.ready)
+	}
+}
+
+// head returns the oldest waiter with the highest priority
+func (l *RateLimiter) head() (Priority, *rateLimitWaiter) {
+	var head *rateLimitWaiter
+	var headPriority Priority
+	for priority, queue := range l.queues {
+		if len(queue) > 0 && (head == nil || priority > headPriority) {
+			head, headPriority = queue[0], priority
+		}
+	}
+	return headPriority, head
+}
+
+// schedule runs dispatch again after the given number of seconds
+func (l *RateLimiter) schedule(seconds float64) {
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+
+	l.timer = time.AfterFunc(time.Duration(seconds*float64(time.Second)), func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		l.dispatch()
+	})
+}
+
+// refillTokens adds the tokens accumulated since the last refill
+func (l *RateLimiter) refillTokens() {
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.refill
+	if l.tokens > l.maxTokens {
+		l.tokens = l.maxTokens
+	}
+	l.last = now
+}
+
+// remove removes a waiter from its queue
+func (l *RateLimiter) remove(priority Priority, waiter *rateLimitWaiter) {
+	queue := l.queues[priority]
+	for i, w := range queue {
+		if w == waiter {
+			l.queues[priority] = append(queue[:i:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// record adds a completed wait to the metrics. Callers hold the mutex.
+func (l *RateLimiter) record(wait time.Duration) {
+	l.stats.Requests++
+	l.stats.TotalWait += wait
+	if wait > time.Millisecond {
+		l.stats.Delayed++
+	}
+	if wait > l.stats.MaxWait {
+		l.stats.MaxWait = wait
+	}
+}
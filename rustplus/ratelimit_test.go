@@ -0,0 +1,100 @@
+package rustplus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitQueued waits until n requests are queued in the limiter
+func waitQueued(t *testing.T, limiter *RateLimiter, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d queued requests", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// grant adds tokens to the limiter and dispatches waiting requests
+func grant(limiter *RateLimiter, tokens float64) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.tokens += tokens
+	limiter.dispatch()
+}
+
+// TestRateLimiter_Priority tests that interactive requests overtake queued bulk requests
+func TestRateLimiter_Priority(t *testing.T) {
+	// Refill slowly enough that only grant releases the queued requests
+	limiter := NewRateLimiter(1, 0.001)
+	defer func() {
+		limiter.mutex.Lock()
+		limiter.timer.Stop()
+		limiter.mutex.Unlock()
+	}()
+
+	// Drain the bucket
+	if err := limiter.Wait(context.Background(), 1, PriorityBulk); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	order := make(chan Priority, 2)
+	go func() {
+		limiter.Wait(context.Background(), 1, PriorityBulk)
+		order <- PriorityBulk
+	}()
+	waitQueued(t, limiter, 1)
+
+	go func() {
+		limiter.Wait(context.Background(), 1, PriorityInteractive)
+		order <- PriorityInteractive
+	}()
+	waitQueued(t, limiter, 2)
+
+	// Both requests must wait long enough to count as delayed
+	time.Sleep(2 * time.Millisecond)
+	grant(limiter, 1)
+	if first := <-order; first != PriorityInteractive {
+		t.Errorf("Expected interactive request first, got %v", first)
+	}
+	grant(limiter, 1)
+	<-order
+
+	stats := limiter.Stats()
+	if stats.Requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", stats.Requests)
+	}
+	if stats.Delayed != 2 {
+		t.Errorf("Expected 2 delayed requests, got %d", stats.Delayed)
+	}
+}
+
+// TestNewRateLimiter_Invalid tests that invalid limits fall back to the player limits
+func TestNewRateLimiter_Invalid(t *testing.T) {
+	limiter := NewRateLimiter(0, -1)
+	if limiter.maxTokens != PlayerRateLimitTokens || limiter.refill != PlayerRateLimitRefill {
+		t.Errorf("Expected player limits, got %v tokens and %v per second", limiter.maxTokens, limiter.refill)
+	}
+}
+
+// TestRateLimiter_Cancel tests that a cancelled wait leaves the queue
+func TestRateLimiter_Cancel(t *testing.T) {
+	limiter := NewRateLimiter(1, 0.1)
+	limiter.Wait(context.Background(), 1, PriorityBulk)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, 1, PriorityBulk); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if queued := limiter.Stats().Queued; queued != 0 {
+		t.Errorf("Expected empty queue, got %d", queued)
+	}
+}
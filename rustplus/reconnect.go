@@ -0,0 +1,173 @@
+package rustplus
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ReconnectForever can be used as ReconnectPolicy.MaxAttempts to retry indefinitely
+const ReconnectForever = -1
+
+// ReconnectPolicy controls how the client reconnects after losing its connection
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of attempts before giving up. Zero disables
+	// reconnecting and ReconnectForever retries indefinitely.
+	MaxAttempts int
+	// InitialDelay is the delay before the first attempt
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts
+	MaxDelay time.Duration
+	// Multiplier is applied to the delay after each failed attempt
+	Multiplier float64
+	// Jitter randomises each delay by up to this fraction, e.g. 0.2 for ±20%
+	Jitter float64
+}
+
+// DefaultReconnectPolicy is the reconnect policy used by new clients
+var DefaultReconnectPolicy = ReconnectPolicy{
+	MaxAttempts:  5,
+	InitialDelay: 1 * time.Second,
+	MaxDelay:     30 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.2,
+}
+
+// Delay returns the delay before the given attempt, starting at 1
+func (p ReconnectPolicy) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// allows reports whether the policy permits the given attempt, starting at 1
+func (p ReconnectPolicy) allows(attempt int) bool {
+	return p.MaxAttempts == ReconnectForever || attempt <= p.MaxAttempts
+}
+
+// reconnect tries to re-establish the connection according to the reconnect
+// policy and restores camera subscriptions and entity interest on success
+func (c *Client) reconnect() {
+	c.connMutex.Lock()
+	if c.reconnecting {
+		c.connMutex.Unlock()
+		return
+	}
+	c.reconnecting = true
+	policy := c.reconnectPolicy
+	c.connMutex.Unlock()
+
+	defer func() {
+		c.connMutex.Lock()
+		c.reconnecting = false
+		c.connMutex.Unlock()
+	}()
+
+	attempt := 1
+	for ; policy.allows(attempt); attempt++ {
+		c.emitEvent(EventReconnecting, attempt, nil)
+
+		select {
+		case <-time.After(policy.Delay(attempt)):
+		case <-c.ctx.Done():
+			return
+		}
+
+		if err := c.Connect(); err != nil {
+			c.emitEvent(EventError, nil, fmt.Errorf("failed to reconnect: %w", err))
+			continue
+		}
+
+		c.emitEvent(EventReconnected, attempt, nil)
+		c.resync()
+		return
+	}
+
+	if attempt > 1 {
+		c.emitEvent(EventError, nil, fmt.Errorf("giving up reconnecting after %d attempts", attempt-1))
+	}
+}
+
+// failPendingRequests completes every request waiting for a response with err
+func (c *Client) failPendingRequests(err error) {
+	c.seqCallbackMutex.Lock()
+	defer c.seqCallbackMutex.Unlock()
+
+	for seq, resultChan := range c.seqCallbacks {
+		resultChan <- requestResult{err: err}
+		delete(c.seqCallbacks, seq)
+	}
+}
+
+// resync re-establishes camera subscriptions and entity interest, which the
+// server forgets when the connection is lost
+func (c *Client) resync() {
+	c.resyncMutex.Lock()
+	cameras := make([]*Camera, 0, len(c.cameras))
+	for camera := range c.cameras {
+		cameras = append(cameras, camera)
+	}
+	entityIDs := make([]uint32, 0, len(c.entityInterest))
+	for entityID := range c.entityInterest {
+		entityIDs = append(entityIDs, entityID)
+	}
+	c.resyncMutex.Unlock()
+
+	for _, camera := range cameras {
+		if err := camera.resubscribe(); err != nil {
+			c.emitEvent(EventError, nil, fmt.Errorf("failed to resubscribe to camera %s: %w", camera.identifier, err))
+		}
+	}
+
+	// The server only broadcasts changes for entities requested on the current connection
+	for _, entityID := range entityIDs {
+		if _, err := c.GetEntityInfoContext(c.ctx, entityID); err != nil {
+			c.emitEvent(EventError, nil, fmt.Errorf("failed to restore interest in entity %d: %w", entityID, err))
+		}
+	}
+}
+
+// addCamera registers a subscribed camera to be restored after reconnecting
+func (c *Client) addCamera(camera *Camera) {
+	c.resyncMutex.Lock()
+	c.cameras[camera] = struct{}{}
+	c.resyncMutex.Unlock()
+}
+
+// removeCamera stops restoring a camera after reconnecting
+func (c *Client) removeCamera(camera *Camera) {
+	c.resyncMutex.Lock()
+	delete(c.cameras, camera)
+	c.resyncMutex.Unlock()
+}
+
+// addEntityInterest registers interest in broadcasts for an entity
+func (c *Client) addEntityInterest(entityID uint32) {
+	c.resyncMutex.Lock()
+	c.entityInterest[entityID]++
+	c.resyncMutex.Unlock()
+}
+
+// removeEntityInterest releases interest in broadcasts for an entity
+func (c *Client) removeEntityInterest(entityID uint32) {
+	c.resyncMutex.Lock()
+	defer c.resyncMutex.Unlock()
+
+	c.entityInterest[entityID]--
+	if c.entityInterest[entityID] <= 0 {
+		delete(c.entityInterest, entityID)
+	}
+}
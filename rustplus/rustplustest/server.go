@@ -0,0 +1,647 @@
+// Package rustplustest provides an in-process Rust+ companion server for
+// testing code built on rustplus.Client without a live game server.
+package rustplustest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus"
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	"github.com/gorilla/websocket"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// Default credentials accepted by a new Server
+const (
+	DefaultPlayerID    uint64 = 76561198000000000
+	DefaultPlayerToken int    = 123456789
+)
+
+// RequestKind identifies the type of an AppRequest
+type RequestKind string
+
+// Request kinds understood by the server
+const (
+	RequestGetInfo           RequestKind = "getInfo"
+	RequestGetTime           RequestKind = "getTime"
+	RequestGetMap            RequestKind = "getMap"
+	RequestGetTeamInfo       RequestKind = "getTeamInfo"
+	RequestGetTeamChat       RequestKind = "getTeamChat"
+	RequestSendTeamMessage   RequestKind = "sendTeamMessage"
+	RequestGetEntityInfo     RequestKind = "getEntityInfo"
+	RequestSetEntityValue    RequestKind = "setEntityValue"
+	RequestCheckSubscription RequestKind = "checkSubscription"
+	RequestSetSubscription   RequestKind = "setSubscription"
+	RequestGetMapMarkers     RequestKind = "getMapMarkers"
+	RequestPromoteToLeader   RequestKind = "promoteToLeader"
+	RequestGetClanInfo       RequestKind = "getClanInfo"
+	RequestSetClanMotd       RequestKind = "setClanMotd"
+	RequestGetClanChat       RequestKind = "getClanChat"
+	RequestSendClanMessage   RequestKind = "sendClanMessage"
+	RequestGetNexusAuth      RequestKind = "getNexusAuth"
+	RequestCameraSubscribe   RequestKind = "cameraSubscribe"
+	RequestCameraUnsubscribe RequestKind = "cameraUnsubscribe"
+	RequestCameraInput       RequestKind = "cameraInput"
+	RequestUnknown           RequestKind = "unknown"
+)
+
+// KindOf returns the kind of a request
+func KindOf(req *proto.AppRequest) RequestKind {
+	switch {
+	case req.GetInfo != nil:
+		return RequestGetInfo
+	case req.GetTime != nil:
+		return RequestGetTime
+	case req.GetMap != nil:
+		return RequestGetMap
+	case req.GetTeamInfo != nil:
+		return RequestGetTeamInfo
+	case req.GetTeamChat != nil:
+		return RequestGetTeamChat
+	case req.SendTeamMessage != nil:
+		return RequestSendTeamMessage
+	case req.GetEntityInfo != nil:
+		return RequestGetEntityInfo
+	case req.SetEntityValue != nil:
+		return RequestSetEntityValue
+	case req.CheckSubscription != nil:
+		return RequestCheckSubscription
+	case req.SetSubscription != nil:
+		return RequestSetSubscription
+	case req.GetMapMarkers != nil:
+		return RequestGetMapMarkers
+	case req.PromoteToLeader != nil:
+		return RequestPromoteToLeader
+	case req.GetClanInfo != nil:
+		return RequestGetClanInfo
+	case req.SetClanMotd != nil:
+		return RequestSetClanMotd
+	case req.GetClanChat != nil:
+		return RequestGetClanChat
+	case req.SendClanMessage != nil:
+		return RequestSendClanMessage
+	case req.GetNexusAuth != nil:
+		return RequestGetNexusAuth
+	case req.CameraSubscribe != nil:
+		return RequestCameraSubscribe
+	case req.CameraUnsubscribe != nil:
+		return RequestCameraUnsubscribe
+	case req.CameraInput != nil:
+		return RequestCameraInput
+	default:
+		return RequestUnknown
+	}
+}
+
+// RequestHandler handles a request before the built-in behaviour. Returning
+// nil falls through to the built-in handling.
+type RequestHandler func(req *proto.AppRequest) *proto.AppResponse
+
+// entity is a scripted smart device
+type entity struct {
+	entityType proto.AppEntityType
+	payload    *proto.AppEntityPayload
+	subscribed bool
+}
+
+// conn is a connected client
+type conn struct {
+	ws         *websocket.Conn
+	writeMutex sync.Mutex
+}
+
+// write sends a message to the client
+func (c *conn) write(msg *proto.AppMessage) error {
+	data, err := protobuf.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	return c.ws.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// Server is an in-process Rust+ companion server
+type Server struct {
+	httpServer *httptest.Server
+	upgrader   websocket.Upgrader
+
+	// Credentials accepted by the server
+	PlayerID    uint64
+	PlayerToken int
+	// PlayerName is used for chat messages sent by the player
+	PlayerName string
+
+	mutex    sync.Mutex
+	conns    map[*conn]struct{}
+	requests []*proto.AppRequest
+	handler  RequestHandler
+	errors   map[RequestKind][]string
+	delays   map[RequestKind]time.Duration
+
+	info       *proto.AppInfo
+	time       *proto.AppTime
+	mapData    *proto.AppMap
+	team       *proto.AppTeamInfo
+	teamChat   []*proto.AppTeamMessage
+	clan       *proto.ClanInfo
+	clanChat   []*proto.AppClanMessage
+	markers    []*proto.AppMarker
+	entities   map[uint32]*entity
+	cameras    map[string]*proto.AppCameraInfo
+	nexusAuths map[string]*proto.AppNexusAuth
+}
+
+// NewServer starts a new server. Close must be called when it is no longer needed.
+func NewServer() *Server {
+	s := &Server{
+		PlayerID:    DefaultPlayerID,
+		PlayerToken: DefaultPlayerToken,
+		PlayerName:  "Player",
+		conns:       make(map[*conn]struct{}),
+		errors:      make(map[RequestKind][]string),
+		delays:      make(map[RequestKind]time.Duration),
+		entities:    make(map[uint32]*entity),
+		cameras:     make(map[string]*proto.AppCameraInfo),
+		nexusAuths:  make(map[string]*proto.AppNexusAuth),
+		info: &proto.AppInfo{
+			Name:        protobuf.String("Test Server"),
+			HeaderImage: protobuf.String(""),
+			Url:         protobuf.String(""),
+			Map:         protobuf.String("Procedural Map"),
+			MapSize:     protobuf.Uint32(4000),
+			WipeTime:    protobuf.Uint32(0),
+			Players:     protobuf.Uint32(0),
+			MaxPlayers:  protobuf.Uint32(100),
+		},
+		time: &proto.AppTime{
+			DayLengthMinutes: protobuf.Float32(60),
+			TimeScale:        protobuf.Float32(1),
+			Sunrise:          protobuf.Float32(7),
+			Sunset:           protobuf.Float32(20),
+			Time:             protobuf.Float32(12),
+		},
+		mapData: &proto.AppMap{
+			Width:       protobuf.Uint32(0),
+			Height:      protobuf.Uint32(0),
+			JpgImage:    []byte{},
+			OceanMargin: protobuf.Int32(0),
+		},
+	}
+	s.team = &proto.AppTeamInfo{
+		LeaderSteamId: protobuf.Uint64(s.PlayerID),
+	}
+
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close disconnects all clients and stops the server
+func (s *Server) Close() {
+	s.Disconnect()
+	s.httpServer.Close()
+}
+
+// Host returns the host the server listens on
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.httpServer.Listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server listens on
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.httpServer.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Client creates a client configured with the server's address and credentials
+func (s *Server) Client(opts ...rustplus.ClientOption) *rustplus.Client {
+	return rustplus.NewClient(s.Host(), s.Port(), s.PlayerID, s.PlayerToken, false, opts...)
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []*proto.AppRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*proto.AppRequest(nil), s.requests...)
+}
+
+// Connections returns the number of connected clients
+func (s *Server) Connections() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.conns)
+}
+
+// SetRequestHandler sets a handler that runs before the built-in behaviour
+func (s *Server) SetRequestHandler(handler RequestHandler) {
+	s.mutex.Lock()
+	s.handler = handler
+	s.mutex.Unlock()
+}
+
+// FailNext makes the next request of the given kind fail with the server
+// error string, e.g. "not_found" or "rate_limit". Calls are queued.
+func (s *Server) FailNext(kind RequestKind, serverError string) {
+	s.mutex.Lock()
+	s.errors[kind] = append(s.errors[kind], serverError)
+	s.mutex.Unlock()
+}
+
+// SetDelay delays responses to requests of the given kind
+func (s *Server) SetDelay(kind RequestKind, delay time.Duration) {
+	s.mutex.Lock()
+	s.delays[kind] = delay
+	s.mutex.Unlock()
+}
+
+// SetInfo sets the response to GetInfo
+func (s *Server) SetInfo(info *proto.AppInfo) {
+	s.mutex.Lock()
+	s.info = info
+	s.mutex.Unlock()
+}
+
+// SetTime sets the response to GetTime
+func (s *Server) SetTime(t *proto.AppTime) {
+	s.mutex.Lock()
+	s.time = t
+	s.mutex.Unlock()
+}
+
+// SetMap sets the response to GetMap
+func (s *Server) SetMap(m *proto.AppMap) {
+	s.mutex.Lock()
+	s.mapData = m
+	s.mutex.Unlock()
+}
+
+// SetMarkers sets the response to GetMapMarkers
+func (s *Server) SetMarkers(markers []*proto.AppMarker) {
+	s.mutex.Lock()
+	s.markers = markers
+	s.mutex.Unlock()
+}
+
+// SetTeamInfo sets the player's team and broadcasts the change
+func (s *Server) SetTeamInfo(team *proto.AppTeamInfo) {
+	s.mutex.Lock()
+	s.team = team
+	s.mutex.Unlock()
+
+	s.Broadcast(&proto.AppBroadcast{
+		TeamChanged: &proto.AppTeamChanged{
+			PlayerId: protobuf.Uint64(s.PlayerID),
+			TeamInfo: team,
+		},
+	})
+}
+
+// SetClanInfo sets the player's clan and broadcasts the change. A nil clan
+// means the player is not in a clan.
+func (s *Server) SetClanInfo(clan *proto.ClanInfo) {
+	s.mutex.Lock()
+	s.clan = clan
+	s.mutex.Unlock()
+
+	s.Broadcast(&proto.AppBroadcast{
+		ClanChanged: &proto.AppClanChanged{ClanInfo: clan},
+	})
+}
+
+// AddTeamMessage adds a message to team chat and broadcasts it
+func (s *Server) AddTeamMessage(steamID uint64, name, message string) {
+	msg := &proto.AppTeamMessage{
+		SteamId: protobuf.Uint64(steamID),
+		Name:    protobuf.String(name),
+		Message: protobuf.String(message),
+		Color:   protobuf.String("#5af"),
+		Time:    protobuf.Uint32(uint32(time.Now().Unix())),
+	}
+
+	s.mutex.Lock()
+	s.teamChat = append(s.teamChat, msg)
+	s.mutex.Unlock()
+
+	s.Broadcast(&proto.AppBroadcast{
+		TeamMessage: &proto.AppNewTeamMessage{Message: msg},
+	})
+}
+
+// AddClanMessage adds a message to clan chat and broadcasts it
+func (s *Server) AddClanMessage(steamID uint64, name, message string) {
+	msg := &proto.AppClanMessage{
+		SteamId: protobuf.Uint64(steamID),
+		Name:    protobuf.String(name),
+		Message: protobuf.String(message),
+		Time:    protobuf.Int64(time.Now().Unix()),
+	}
+
+	s.mutex.Lock()
+	s.clanChat = append(s.clanChat, msg)
+	clanID := s.clan.GetClanId()
+	s.mutex.Unlock()
+
+	s.Broadcast(&proto.AppBroadcast{
+		ClanMessage: &proto.AppNewClanMessage{
+			ClanId:  protobuf.Int64(clanID),
+			Message: msg,
+		},
+	})
+}
+
+// AddEntity adds a smart device
+func (s *Server) AddEntity(entityID uint32, entityType proto.AppEntityType, payload *proto.AppEntityPayload) {
+	if payload == nil {
+		payload = &proto.AppEntityPayload{}
+	}
+
+	s.mutex.Lock()
+	s.entities[entityID] = &entity{entityType: entityType, payload: payload}
+	s.mutex.Unlock()
+}
+
+// SetEntityPayload updates a smart device and broadcasts the change
+func (s *Server) SetEntityPayload(entityID uint32, payload *proto.AppEntityPayload) {
+	s.mutex.Lock()
+	e, ok := s.entities[entityID]
+	if ok {
+		e.payload = payload
+	}
+	s.mutex.Unlock()
+
+	if ok {
+		s.broadcastEntity(entityID, payload)
+	}
+}
+
+// AddCamera adds a camera that can be subscribed to
+func (s *Server) AddCamera(cameraID string, info *proto.AppCameraInfo) {
+	s.mutex.Lock()
+	s.cameras[cameraID] = info
+	s.mutex.Unlock()
+}
+
+// SendCameraRays broadcasts camera rays to all clients
+func (s *Server) SendCameraRays(rays *proto.AppCameraRays) {
+	s.Broadcast(&proto.AppBroadcast{CameraRays: rays})
+}
+
+// SetNexusAuth sets the response to GetNexusAuth for the given app key
+func (s *Server) SetNexusAuth(appKey string, auth *proto.AppNexusAuth) {
+	s.mutex.Lock()
+	s.nexusAuths[appKey] = auth
+	s.mutex.Unlock()
+}
+
+// Broadcast sends a broadcast to all connected clients
+func (s *Server) Broadcast(broadcast *proto.AppBroadcast) {
+	for _, c := range s.connections() {
+		c.write(&proto.AppMessage{Broadcast: broadcast})
+	}
+}
+
+// Disconnect closes all client connections
+func (s *Server) Disconnect() {
+	for _, c := range s.connections() {
+		c.ws.Close()
+	}
+}
+
+// connections returns a snapshot of the connected clients
+func (s *Server) connections() []*conn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// serveHTTP upgrades a client connection and serves its requests
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("playerid") != strconv.FormatUint(s.PlayerID, 10) || q.Get("playertoken") != strconv.Itoa(s.PlayerToken) {
+		http.Error(w, "invalid player credentials", http.StatusUnauthorized)
+		return
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &conn{ws: ws}
+	s.mutex.Lock()
+	s.conns[c] = struct{}{}
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, c)
+		s.mutex.Unlock()
+		ws.Close()
+	}()
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		req := &proto.AppRequest{}
+		if err := protobuf.Unmarshal(data, req); err != nil {
+			continue
+		}
+
+		s.mutex.Lock()
+		s.requests = append(s.requests, req)
+		delay := s.delays[KindOf(req)]
+		s.mutex.Unlock()
+
+		if delay > 0 {
+			go func() {
+				time.Sleep(delay)
+				c.write(&proto.AppMessage{Response: s.respond(req)})
+			}()
+			continue
+		}
+
+		c.write(&proto.AppMessage{Response: s.respond(req)})
+	}
+}
+
+// respond builds the response to a request
+func (s *Server) respond(req *proto.AppRequest) *proto.AppResponse {
+	response := s.handle(req)
+	response.Seq = protobuf.Uint32(req.GetSeq())
+	return response
+}
+
+// handle runs the request handler, injected errors and built-in behaviour
+func (s *Server) handle(req *proto.AppRequest) *proto.AppResponse {
+	kind := KindOf(req)
+
+	s.mutex.Lock()
+	handler := s.handler
+	var injected string
+	if queued := s.errors[kind]; len(queued) > 0 {
+		injected = queued[0]
+		s.errors[kind] = queued[1:]
+	}
+	s.mutex.Unlock()
+
+	if injected != "" {
+		return errorResponse(injected)
+	}
+
+	if handler != nil {
+		if response := handler(req); response != nil {
+			return response
+		}
+	}
+
+	if req.GetPlayerId() != s.PlayerID || int(req.GetPlayerToken()) != s.PlayerToken {
+		return errorResponse("access_denied")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch kind {
+	case RequestGetInfo:
+		return &proto.AppResponse{Info: s.info}
+	case RequestGetTime:
+		return &proto.AppResponse{Time: s.time}
+	case RequestGetMap:
+		return &proto.AppResponse{Map: s.mapData}
+	case RequestGetTeamInfo:
+		return &proto.AppResponse{TeamInfo: s.team}
+	case RequestGetTeamChat:
+		return &proto.AppResponse{TeamChat: &proto.AppTeamChat{Messages: s.teamChat}}
+	case RequestSendTeamMessage:
+		go s.AddTeamMessage(s.PlayerID, s.PlayerName, req.GetSendTeamMessage().GetMessage())
+		return successResponse()
+	case RequestGetEntityInfo:
+		e, ok := s.entities[req.GetEntityId()]
+		if !ok {
+			return errorResponse("not_found")
+		}
+		return &proto.AppResponse{
+			EntityInfo: &proto.AppEntityInfo{
+				Type:    e.entityType.Enum(),
+				Payload: e.payload,
+			},
+		}
+	case RequestSetEntityValue:
+		e, ok := s.entities[req.GetEntityId()]
+		if !ok {
+			return errorResponse("not_found")
+		}
+		if e.entityType != proto.AppEntityType_Switch {
+			return errorResponse("wrong_type")
+		}
+		payload := protobuf.Clone(e.payload).(*proto.AppEntityPayload)
+		payload.Value = protobuf.Bool(req.GetSetEntityValue().GetValue())
+		e.payload = payload
+		go s.broadcastEntity(req.GetEntityId(), payload)
+		return successResponse()
+	case RequestCheckSubscription:
+		e, ok := s.entities[req.GetEntityId()]
+		if !ok {
+			return errorResponse("not_found")
+		}
+		return &proto.AppResponse{Flag: &proto.AppFlag{Value: protobuf.Bool(e.subscribed)}}
+	case RequestSetSubscription:
+		e, ok := s.entities[req.GetEntityId()]
+		if !ok {
+			return errorResponse("not_found")
+		}
+		e.subscribed = req.GetSetSubscription().GetValue()
+		return successResponse()
+	case RequestGetMapMarkers:
+		return &proto.AppResponse{MapMarkers: &proto.AppMapMarkers{Markers: s.markers}}
+	case RequestPromoteToLeader:
+		steamID := req.GetPromoteToLeader().GetSteamId()
+		for _, member := range s.team.GetMembers() {
+			if member.GetSteamId() == steamID {
+				// Responses already handed out share the old team, so replace a copy
+				team := protobuf.Clone(s.team).(*proto.AppTeamInfo)
+				team.LeaderSteamId = protobuf.Uint64(steamID)
+				s.team = team
+				return successResponse()
+			}
+		}
+		return errorResponse("no_player")
+	case RequestGetClanInfo:
+		if s.clan == nil {
+			return errorResponse("no_clan")
+		}
+		return &proto.AppResponse{ClanInfo: &proto.AppClanInfo{ClanInfo: s.clan}}
+	case RequestSetClanMotd:
+		if s.clan == nil {
+			return errorResponse("no_clan")
+		}
+		clan := protobuf.Clone(s.clan).(*proto.ClanInfo)
+		clan.Motd = protobuf.String(req.GetSetClanMotd().GetMessage())
+		s.clan = clan
+		return successResponse()
+	case RequestGetClanChat:
+		if s.clan == nil {
+			return errorResponse("no_clan")
+		}
+		return &proto.AppResponse{ClanChat: &proto.AppClanChat{Messages: s.clanChat}}
+	case RequestSendClanMessage:
+		if s.clan == nil {
+			return errorResponse("no_clan")
+		}
+		go s.AddClanMessage(s.PlayerID, s.PlayerName, req.GetSendClanMessage().GetMessage())
+		return successResponse()
+	case RequestGetNexusAuth:
+		auth, ok := s.nexusAuths[req.GetGetNexusAuth().GetAppKey()]
+		if !ok {
+			return errorResponse("not_found")
+		}
+		return &proto.AppResponse{NexusAuth: auth}
+	case RequestCameraSubscribe:
+		info, ok := s.cameras[req.GetCameraSubscribe().GetCameraId()]
+		if !ok {
+			return errorResponse("not_found")
+		}
+		return &proto.AppResponse{CameraSubscribeInfo: info}
+	case RequestCameraUnsubscribe, RequestCameraInput:
+		return successResponse()
+	default:
+		return errorResponse("server_error")
+	}
+}
+
+// broadcastEntity broadcasts a change to a smart device
+func (s *Server) broadcastEntity(entityID uint32, payload *proto.AppEntityPayload) {
+	s.Broadcast(&proto.AppBroadcast{
+		EntityChanged: &proto.AppEntityChanged{
+			EntityId: protobuf.Uint32(entityID),
+			Payload:  payload,
+		},
+	})
+}
+
+// successResponse creates an empty successful response
+func successResponse() *proto.AppResponse {
+	return &proto.AppResponse{Success: &proto.AppSuccess{}}
+}
+
+// errorResponse creates a response carrying a server error string
+func errorResponse(serverError string) *proto.AppResponse {
+	return &proto.AppResponse{Error: &proto.AppError{Error: protobuf.String(serverError)}}
+}
@@ -0,0 +1,258 @@
+package rustplus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// DefaultTimeResync is how often a DayNightTicker fetches the server time
+// again to correct drift
+const DefaultTimeResync = 5 * time.Minute
+
+// ServerTime is the in-game time of day reported by GetTime, together with
+// when it was fetched so it can be projected forward
+type ServerTime struct {
+	// DayLength is the real duration of a full 24 hour in-game day
+	DayLength time.Duration
+	// TimeScale speeds up or slows down the in-game clock
+	TimeScale float32
+	// Sunrise and Sunset are the in-game hours the sun rises and sets
+	Sunrise float32
+	Sunset  float32
+	// Time is the in-game hour, from 0 to 24
+	Time float32
+	// FetchedAt is the real time Time was reported
+	FetchedAt time.Time
+}
+
+// NewServerTime converts the time returned by GetTime, fetched at the given real time
+func NewServerTime(t *proto.AppTime, fetchedAt time.Time) ServerTime {
+	return ServerTime{
+		DayLength: time.Duration(float64(t.GetDayLengthMinutes()) * float64(time.Minute)),
+		TimeScale: t.GetTimeScale(),
+		Sunrise:   t.GetSunrise(),
+		Sunset:    t.GetSunset(),
+		Time:      t.GetTime(),
+		FetchedAt: fetchedAt,
+	}
+}
+
+// GetServerTime gets the in-game time of day
+func (c *Client) GetServerTime() (ServerTime, error) {
+	return c.GetServerTimeContext(context.Background())
+}
+
+// GetServerTimeContext gets the in-game time of day using the provided context
+func (c *Client) GetServerTimeContext(ctx context.Context) (ServerTime, error) {
+	t, err := c.GetTimeContext(ctx)
+	if err != nil {
+		return ServerTime{}, err
+	}
+	return NewServerTime(t, time.Now()), nil
+}
+
+// HourDuration returns the real duration of one in-game hour. This assumes
+// the clock runs at a constant rate through the day and night.
+func (t ServerTime) HourDuration() time.Duration {
+	scale := float64(t.TimeScale)
+	if scale <= 0 {
+		scale = 1
+	}
+	return time.Duration(float64(t.DayLength) / 24 / scale)
+}
+
+// At projects the in-game time to the given real time
+func (t ServerTime) At(now time.Time) ServerTime {
+	hour := t.HourDuration()
+	if hour <= 0 {
+		return t
+	}
+
+	elapsed := float64(now.Sub(t.FetchedAt)) / float64(hour)
+	t.Time = float32(math.Mod(math.Mod(float64(t.Time)+elapsed, 24)+24, 24))
+	t.FetchedAt = now
+	return t
+}
+
+// Now projects the in-game time to the current real time
+func (t ServerTime) Now() ServerTime {
+	return t.At(time.Now())
+}
+
+// IsDay reports whether the sun is up
+func (t ServerTime) IsDay() bool {
+	return t.Time >= t.Sunrise && t.Time < t.Sunset
+}
+
+// Clock returns the in-game time as a 24 hour clock string, such as "14:05"
+func (t ServerTime) Clock() string {
+	return FormatClock(t.Time)
+}
+
+// UntilHour returns the real time until the in-game clock next reaches the given hour
+func (t ServerTime) UntilHour(hour float32) time.Duration {
+	hours := math.Mod(float64(hour-t.Time)+24, 24)
+	return time.Duration(hours * float64(t.HourDuration()))
+}
+
+// UntilSunrise returns the real time until the sun next rises
+func (t ServerTime) UntilSunrise() time.Duration {
+	return t.UntilHour(t.Sunrise)
+}
+
+// UntilSunset returns the real time until the sun next sets
+func (t ServerTime) UntilSunset() time.Duration {
+	return t.UntilHour(t.Sunset)
+}
+
+// UntilChange returns the real time until the next sunrise or sunset,
+// whichever comes first
+func (t ServerTime) UntilChange() time.Duration {
+	if t.IsDay() {
+		return t.UntilSunset()
+	}
+	return t.UntilSunrise()
+}
+
+// FormatClock formats an in-game hour as a 24 hour clock string, such as "14:05"
+func FormatClock(hours float32) string {
+	minutes := int(math.Floor(float64(hours)*60)) % (24 * 60)
+	if minutes < 0 {
+		minutes += 24 * 60
+	}
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// DayPhase is the part of the day a DayNightEvent starts
+type DayPhase string
+
+const (
+	// PhaseDawn is when the sun rises
+	PhaseDawn DayPhase = "dawn"
+	// PhaseDusk is when the sun sets
+	PhaseDusk DayPhase = "dusk"
+)
+
+// DayNightEvent is emitted by a DayNightTicker when the sun rises or sets
+type DayNightEvent struct {
+	Phase DayPhase
+	Time  ServerTime
+}
+
+// DayNightTicker watches the server time and emits events at dawn and dusk
+type DayNightTicker struct {
+	// Resync is how often the server time is fetched again to correct drift
+	Resync time.Duration
+
+	client    *Client
+	eventChan chan DayNightEvent
+	mutex     sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewDayNightTicker creates a dawn and dusk ticker for the client
+func NewDayNightTicker(client *Client) *DayNightTicker {
+	return &DayNightTicker{
+		Resync:    DefaultTimeResync,
+		client:    client,
+		eventChan: make(chan DayNightEvent, 10),
+	}
+}
+
+// Events returns a channel of dawn and dusk events
+func (t *DayNightTicker) Events() <-chan DayNightEvent {
+	return t.eventChan
+}
+
+// Start fetches the server time and emits events at dawn and dusk until
+// Stop is called or ctx is done
+func (t *DayNightTicker) Start(ctx context.Context) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.cancel != nil {
+		return fmt.Errorf("day night ticker already started")
+	}
+
+	current, err := t.client.GetServerTimeContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get time: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	go t.run(ctx, current, t.done)
+
+	return nil
+}
+
+// Stop stops the ticker
+func (t *DayNightTicker) Stop() {
+	t.mutex.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	t.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// run waits for each sunrise and sunset, fetching the time again to confirm
+// the change before emitting it
+func (t *DayNightTicker) run(ctx context.Context, current ServerTime, done chan struct{}) {
+	defer close(done)
+
+	wasDay := current.IsDay()
+	for {
+		// Wake up just after the projected change, or earlier to resync
+		wait := current.Now().UntilChange() + time.Second
+		if t.Resync > 0 && wait > t.Resync {
+			wait = t.Resync
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		latest, err := t.client.GetServerTimeContext(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Printf("Warning: Failed to get server time: %v\n", err)
+			}
+			// Keep projecting from the last known time
+			continue
+		}
+		current = latest
+
+		if current.IsDay() == wasDay {
+			continue
+		}
+		wasDay = current.IsDay()
+
+		event := DayNightEvent{Phase: PhaseDusk, Time: current}
+		if wasDay {
+			event.Phase = PhaseDawn
+		}
+
+		select {
+		case t.eventChan <- event:
+			// Event sent successfully
+		default:
+			// Channel is full, log the error
+			fmt.Printf("Warning: Day night event channel is full, event %s dropped\n", event.Phase)
+		}
+	}
+}
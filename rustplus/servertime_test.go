@@ -0,0 +1,50 @@
+package rustplus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// TestServerTime tests projecting the in-game clock
+func TestServerTime(t *testing.T) {
+	fetched := time.Now()
+	st := NewServerTime(&proto.AppTime{
+		DayLengthMinutes: protobuf.Float32(48),
+		TimeScale:        protobuf.Float32(1),
+		Sunrise:          protobuf.Float32(7.5),
+		Sunset:           protobuf.Float32(19.5),
+		Time:             protobuf.Float32(18.25),
+	}, fetched)
+
+	// 48 real minutes per day is 2 real minutes per in-game hour
+	if got := st.HourDuration(); got != 2*time.Minute {
+		t.Fatalf("Expected 2m per hour, got %v", got)
+	}
+	if got := st.Clock(); got != "18:15" {
+		t.Errorf("Expected clock 18:15, got %s", got)
+	}
+	if !st.IsDay() {
+		t.Error("Expected it to be day")
+	}
+	if got := st.UntilSunset(); got != 150*time.Second {
+		t.Errorf("Expected 2m30s until sunset, got %v", got)
+	}
+	if got := st.UntilChange(); got != st.UntilSunset() {
+		t.Errorf("Expected the next change to be sunset, got %v", got)
+	}
+
+	// Six hours later the clock wraps into the night
+	later := st.At(fetched.Add(12 * time.Minute))
+	if got := later.Clock(); got != "00:15" {
+		t.Errorf("Expected clock 00:15, got %s", got)
+	}
+	if later.IsDay() {
+		t.Error("Expected it to be night")
+	}
+	if got := later.UntilSunrise(); got != 14*time.Minute+30*time.Second {
+		t.Errorf("Expected 14m30s until sunrise, got %v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package rustplus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// CheckSubscription reports whether the player receives push notifications
+// from a Smart Alarm or Storage Monitor
+func (c *Client) CheckSubscription(entityID uint32) (bool, error) {
+	return c.CheckSubscriptionContext(context.Background(), entityID)
+}
+
+// CheckSubscriptionContext reports whether the player receives push
+// notifications from an entity using the provided context
+func (c *Client) CheckSubscriptionContext(ctx context.Context, entityID uint32) (bool, error) {
+	request := &proto.AppRequest{
+		EntityId:          protobuf.Uint32(entityID),
+		CheckSubscription: &proto.AppEmpty{},
+	}
+
+	response, err := c.request(ctx, request)
+	if err != nil {
+		return false, err
+	}
+
+	if response.Flag == nil {
+		return false, ErrInvalidResponse
+	}
+
+	return response.GetFlag().GetValue(), nil
+}
+
+// SetSubscription enables or disables push notifications from a Smart Alarm
+// or Storage Monitor for the player
+func (c *Client) SetSubscription(entityID uint32, subscribed bool) error {
+	return c.SetSubscriptionContext(context.Background(), entityID, subscribed)
+}
+
+// SetSubscriptionContext enables or disables push notifications from an
+// entity using the provided context
+func (c *Client) SetSubscriptionContext(ctx context.Context, entityID uint32, subscribed bool) error {
+	request := &proto.AppRequest{
+		EntityId: protobuf.Uint32(entityID),
+		SetSubscription: &proto.AppFlag{
+			Value: protobuf.Bool(subscribed),
+		},
+	}
+
+	_, err := c.request(ctx, request)
+	return err
+}
+
+// PlayerID returns the Steam ID of the player the client authenticates as
+func (c *Client) PlayerID() uint64 {
+	return c.playerID
+}
+
+// SubscriptionStatus is the result of auditing or reconciling one entity
+type SubscriptionStatus struct {
+	PlayerID uint64
+	EntityID uint32
+	// Desired is whether the entity should push notifications to the player
+	Desired bool
+	// Subscribed is whether the entity pushes notifications to the player
+	// after the audit or reconciliation
+	Subscribed bool
+	// Changed is set when the subscription was updated on the server
+	Changed bool
+	// Err is set when the entity could not be checked or updated
+	Err error
+}
+
+// SubscriptionManager reconciles the push notification subscriptions of a
+// set of entities against a desired state
+type SubscriptionManager struct {
+	client  *Client
+	desired map[uint32]bool
+	mutex   sync.Mutex
+}
+
+// NewSubscriptionManager creates a new subscription manager for the client
+func NewSubscriptionManager(client *Client) *SubscriptionManager {
+	return &SubscriptionManager{
+		client:  client,
+		desired: make(map[uint32]bool),
+	}
+}
+
+// Subscribe marks entities as desired to push notifications
+func (m *SubscriptionManager) Subscribe(entityIDs ...uint32) {
+	m.setDesired(true, entityIDs)
+}
+
+// Unsubscribe marks entities as desired not to push notifications
+func (m *SubscriptionManager) Unsubscribe(entityIDs ...uint32) {
+	m.setDesired(false, entityIDs)
+}
+
+// Forget stops managing entities
+func (m *SubscriptionManager) Forget(entityIDs ...uint32) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, entityID := range entityIDs {
+		delete(m.desired, entityID)
+	}
+}
+
+// Audit checks the subscription of every managed entity without changing it.
+// The returned error joins the errors of all entities that could not be checked.
+func (m *SubscriptionManager) Audit(ctx context.Context) ([]SubscriptionStatus, error) {
+	return m.run(ctx, false)
+}
+
+// Reconcile checks the subscription of every managed entity and updates
+// those that differ from the desired state. The returned error joins the
+// errors of all entities that could not be reconciled.
+func (m *SubscriptionManager) Reconcile(ctx context.Context) ([]SubscriptionStatus, error) {
+	return m.run(ctx, true)
+}
+
+// setDesired records the desired state of entities
+func (m *SubscriptionManager) setDesired(subscribed bool, entityIDs []uint32) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, entityID := range entityIDs {
+		m.desired[entityID] = subscribed
+	}
+}
+
+// run audits every managed entity, updating mismatches if fix is set
+func (m *SubscriptionManager) run(ctx context.Context, fix bool) ([]SubscriptionStatus, error) {
+	m.mutex.Lock()
+	statuses := make([]SubscriptionStatus, 0, len(m.desired))
+	for entityID, desired := range m.desired {
+		statuses = append(statuses, SubscriptionStatus{
+			PlayerID: m.client.PlayerID(),
+			EntityID: entityID,
+			Desired:  desired,
+		})
+	}
+	m.mutex.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].EntityID < statuses[j].EntityID
+	})
+
+	var errs []error
+	for i := range statuses {
+		status := &statuses[i]
+
+		subscribed, err := m.client.CheckSubscriptionContext(ctx, status.EntityID)
+		if err != nil {
+			status.Err = fmt.Errorf("failed to check subscription for entity %d: %w", status.EntityID, err)
+			errs = append(errs, status.Err)
+			continue
+		}
+		status.Subscribed = subscribed
+
+		if !fix || subscribed == status.Desired {
+			continue
+		}
+
+		if err := m.client.SetSubscriptionContext(ctx, status.EntityID, status.Desired); err != nil {
+			status.Err = fmt.Errorf("failed to set subscription for entity %d: %w", status.EntityID, err)
+			errs = append(errs, status.Err)
+			continue
+		}
+		status.Subscribed = status.Desired
+		status.Changed = true
+	}
+
+	return statuses, errors.Join(errs...)
+}
@@ -0,0 +1,233 @@
+package rustplus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// TeamChangeType represents the type of change between two team snapshots
+type TeamChangeType string
+
+const (
+	// TeamMemberJoined is emitted when a player joins the team
+	TeamMemberJoined TeamChangeType = "member_joined"
+	// TeamMemberLeft is emitted when a player leaves the team
+	TeamMemberLeft TeamChangeType = "member_left"
+	// TeamMemberOnline is emitted when a team member comes online
+	TeamMemberOnline TeamChangeType = "member_online"
+	// TeamMemberOffline is emitted when a team member goes offline
+	TeamMemberOffline TeamChangeType = "member_offline"
+	// TeamMemberDied is emitted when a team member dies
+	TeamMemberDied TeamChangeType = "member_died"
+	// TeamMemberRespawned is emitted when a team member respawns
+	TeamMemberRespawned TeamChangeType = "member_respawned"
+	// TeamLeaderChanged is emitted when the team leader changes
+	TeamLeaderChanged TeamChangeType = "leader_changed"
+)
+
+// TeamChange describes a single change between two team snapshots
+type TeamChange struct {
+	Type TeamChangeType
+	// SteamID is the member the change applies to, or the new leader for TeamLeaderChanged
+	SteamID uint64
+	// Member is the member's latest state; for TeamMemberLeft it is the last known state
+	Member *proto.AppTeamInfo_Member
+}
+
+// DiffTeamInfo compares two team snapshots and returns the changes between them.
+// A nil previous snapshot is treated as an empty team.
+func DiffTeamInfo(previous, current *proto.AppTeamInfo) []TeamChange {
+	var changes []TeamChange
+
+	previousMembers := make(map[uint64]*proto.AppTeamInfo_Member)
+	for _, member := range previous.GetMembers() {
+		previousMembers[member.GetSteamId()] = member
+	}
+
+	currentMembers := make(map[uint64]bool)
+	for _, member := range current.GetMembers() {
+		steamID := member.GetSteamId()
+		currentMembers[steamID] = true
+
+		old, ok := previousMembers[steamID]
+		if !ok {
+			changes = append(changes, TeamChange{Type: TeamMemberJoined, SteamID: steamID, Member: member})
+			continue
+		}
+
+		if !old.GetIsOnline() && member.GetIsOnline() {
+			changes = append(changes, TeamChange{Type: TeamMemberOnline, SteamID: steamID, Member: member})
+		} else if old.GetIsOnline() && !member.GetIsOnline() {
+			changes = append(changes, TeamChange{Type: TeamMemberOffline, SteamID: steamID, Member: member})
+		}
+
+		if old.GetIsAlive() && !member.GetIsAlive() {
+			changes = append(changes, TeamChange{Type: TeamMemberDied, SteamID: steamID, Member: member})
+		} else if !old.GetIsAlive() && member.GetIsAlive() {
+			changes = append(changes, TeamChange{Type: TeamMemberRespawned, SteamID: steamID, Member: member})
+		}
+	}
+
+	for _, member := range previous.GetMembers() {
+		if !currentMembers[member.GetSteamId()] {
+			changes = append(changes, TeamChange{Type: TeamMemberLeft, SteamID: member.GetSteamId(), Member: member})
+		}
+	}
+
+	if previous != nil && previous.GetLeaderSteamId() != current.GetLeaderSteamId() {
+		leaderID := current.GetLeaderSteamId()
+		var leader *proto.AppTeamInfo_Member
+		for _, member := range current.GetMembers() {
+			if member.GetSteamId() == leaderID {
+				leader = member
+				break
+			}
+		}
+		changes = append(changes, TeamChange{Type: TeamLeaderChanged, SteamID: leaderID, Member: leader})
+	}
+
+	return changes
+}
+
+// TeamTracker keeps the latest team snapshot and emits the changes between
+// consecutive snapshots received in TeamChanged broadcasts
+type TeamTracker struct {
+	client        *Client
+	current       *proto.AppTeamInfo
+	mutex         sync.RWMutex
+	changeChan    chan TeamChange
+	removeHandler func()
+	started       bool
+	stopped       bool
+}
+
+// NewTeamTracker creates a new team tracker for the client
+func NewTeamTracker(client *Client) *TeamTracker {
+	return &TeamTracker{
+		client:     client,
+		changeChan: make(chan TeamChange, 100),
+	}
+}
+
+// Changes returns a channel of team changes. It is closed by Stop.
+func (t *TeamTracker) Changes() <-chan TeamChange {
+	return t.changeChan
+}
+
+// Start fetches the initial team snapshot and begins tracking TeamChanged
+// broadcasts. If the snapshot cannot be fetched the tracker is left as it
+// was, so Start can be retried.
+func (t *TeamTracker) Start(ctx context.Context) error {
+	t.mutex.Lock()
+	if t.stopped {
+		t.mutex.Unlock()
+		return fmt.Errorf("team tracker stopped")
+	}
+	if t.started {
+		t.mutex.Unlock()
+		return fmt.Errorf("team tracker already started")
+	}
+	t.started = true
+	t.mutex.Unlock()
+
+	// Listen before fetching so no broadcast is missed in between
+	removeHandler := t.client.OnTeamChanged(func(changed *proto.AppTeamChanged) {
+		t.Update(changed.GetTeamInfo())
+	})
+
+	info, err := t.client.GetTeamInfoContext(ctx)
+	if err != nil {
+		removeHandler()
+		t.mutex.Lock()
+		t.started = false
+		t.mutex.Unlock()
+		return fmt.Errorf("failed to get team info: %w", err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// Stop may have run while fetching
+	if t.stopped {
+		removeHandler()
+		return fmt.Errorf("team tracker stopped")
+	}
+	t.removeHandler = removeHandler
+	if t.current == nil {
+		t.current = info
+	}
+
+	return nil
+}
+
+// Stop stops tracking TeamChanged broadcasts and closes the changes channel
+func (t *TeamTracker) Stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stopped {
+		return
+	}
+	t.stopped = true
+	if t.removeHandler != nil {
+		t.removeHandler()
+		t.removeHandler = nil
+	}
+	close(t.changeChan)
+}
+
+// Team returns the latest team snapshot
+func (t *TeamTracker) Team() *proto.AppTeamInfo {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.current
+}
+
+// Update replaces the current snapshot and emits the changes from the previous one
+func (t *TeamTracker) Update(info *proto.AppTeamInfo) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stopped {
+		return
+	}
+
+	previous := t.current
+	t.current = info
+
+	// Without a baseline every member would be reported as joined
+	if previous == nil {
+		return
+	}
+
+	for _, change := range DiffTeamInfo(previous, info) {
+		select {
+		case t.changeChan <- change:
+			// Change sent successfully
+		default:
+			// Channel is full, log the error
+			fmt.Printf("Warning: Team change channel is full, change %s dropped\n", change.Type)
+		}
+	}
+}
+
+// PromoteToLeader promotes a team member to team leader
+func (c *Client) PromoteToLeader(steamID uint64) error {
+	return c.PromoteToLeaderContext(context.Background(), steamID)
+}
+
+// PromoteToLeaderContext promotes a team member to team leader using the provided context
+func (c *Client) PromoteToLeaderContext(ctx context.Context, steamID uint64) error {
+	request := &proto.AppRequest{
+		PromoteToLeader: &proto.AppPromoteToLeader{
+			SteamId: protobuf.Uint64(steamID),
+		},
+	}
+
+	_, err := c.request(ctx, request)
+	return err
+}
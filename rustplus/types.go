@@ -42,9 +42,13 @@ const (
 	EventConnecting   EventType = "connecting"
 	EventConnected    EventType = "connected"
 	EventDisconnected EventType = "disconnected"
+	EventReconnecting EventType = "reconnecting"
+	EventReconnected  EventType = "reconnected"
 	EventMessage      EventType = "message"
 	EventRequest      EventType = "request"
 	EventError        EventType = "error"
+	EventClanChanged  EventType = "clan_changed"
+	EventClanMessage  EventType = "clan_message"
 )
 
 // Event represents an event emitted by the client
@@ -5,76 +5,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 )
 
-// ParsePairingNotification parses a pairing notification from FCM
+// ParsePairingNotification parses the server from a pairing notification from
+// FCM. Use ParsePairing for the entity as well.
 func ParsePairingNotification(notification map[string]interface{}) (string, int, uint64, int, error) {
-	// Extract the data from the notification
-	data, ok := notification["data"].(map[string]interface{})
-	if !ok {
-		return "", 0, 0, 0, fmt.Errorf("invalid notification format: missing data")
-	}
-
-	// Extract the body from the data
-	bodyStr, ok := data["body"].(string)
-	if !ok {
-		return "", 0, 0, 0, fmt.Errorf("invalid notification format: missing body")
-	}
-
-	// Parse the body as JSON
-	var body map[string]interface{}
-	if err := json.Unmarshal([]byte(bodyStr), &body); err != nil {
-		return "", 0, 0, 0, fmt.Errorf("invalid notification format: body is not valid JSON: %w", err)
-	}
-
-	// Extract the server information
-	serverStr, ok := body["server"].(string)
-	if !ok {
-		return "", 0, 0, 0, fmt.Errorf("invalid notification format: missing server")
-	}
-
-	// Extract the player token
-	var playerToken int
-	playerTokenVal, ok := body["playerToken"]
-	if ok {
-		switch v := playerTokenVal.(type) {
-		case float64:
-			playerToken = int(v)
-		case string:
-			if _, err := fmt.Sscanf(v, "%d", &playerToken); err != nil {
-				return "", 0, 0, 0, fmt.Errorf("invalid notification format: playerToken is not a valid number: %w", err)
-			}
-		default:
-			return "", 0, 0, 0, fmt.Errorf("invalid notification format: playerToken has unexpected type")
-		}
-	} else {
-		return "", 0, 0, 0, fmt.Errorf("invalid notification format: missing playerToken")
-	}
-
-	// Extract the player ID
-	playerIDStr, ok := body["playerId"].(string)
-	if !ok {
-		return "", 0, 0, 0, fmt.Errorf("invalid notification format: missing playerId")
-	}
-	var playerID uint64
-	if _, err := fmt.Sscanf(playerIDStr, "%d", &playerID); err != nil {
-		return "", 0, 0, 0, fmt.Errorf("invalid notification format: playerId is not a valid number: %w", err)
-	}
-
-	// Parse the server string to extract the IP and port
-	parts := strings.Split(serverStr, ":")
-	if len(parts) != 2 {
-		return "", 0, 0, 0, fmt.Errorf("invalid server format: %s", serverStr)
-	}
-
-	server := parts[0]
-	var port int
-	if _, err := fmt.Sscanf(parts[1], "%d", &port); err != nil {
-		return "", 0, 0, 0, fmt.Errorf("invalid server format: port is not a valid number: %w", err)
+	p, err := ParsePairing(notification)
+	if err != nil {
+		return "", 0, 0, 0, err
 	}
 
-	return server, port, playerID, playerToken, nil
+	return p.Server, p.Port, p.PlayerID, p.PlayerToken, nil
 }
 
 // GetServerInfo gets information about a Rust server from the Steam API
@@ -0,0 +1,334 @@
+package rustplus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+)
+
+// WorldEventType is the type of a world event detected from map markers
+type WorldEventType string
+
+const (
+	// WorldEventSpawned is emitted when an event marker appears
+	WorldEventSpawned WorldEventType = "spawned"
+	// WorldEventDespawned is emitted when an event marker disappears
+	WorldEventDespawned WorldEventType = "despawned"
+	// WorldEventMoved is emitted when an event marker moves
+	WorldEventMoved WorldEventType = "moved"
+	// WorldEventHeliDowned is emitted when a patrol helicopter disappears and
+	// an explosion marker appears where it was
+	WorldEventHeliDowned WorldEventType = "heli_downed"
+)
+
+// Defaults used by NewWorldEventDetector
+const (
+	DefaultMoveThreshold    = 5   // metres
+	DefaultMonumentRadius   = 250 // metres
+	DefaultHeliDownedRadius = 400 // metres
+	DefaultHeliDownedWindow = 2 * time.Minute
+	DefaultWorldEventPoll   = 30 * time.Second
+)
+
+// WorldEvent is a change to the world event markers on the map
+type WorldEvent struct {
+	Type WorldEventType
+	Time time.Time
+	// Marker is the marker the event is about. For despawns it is the last
+	// position seen, and for downed helicopters it is the explosion.
+	Marker Marker
+	// Previous is the marker before it moved, or the helicopter that was downed
+	Previous *Marker
+	// Grid is the grid reference of the marker, empty when it is out at sea
+	Grid string
+	// Monument is the monument the marker is at, or nil
+	Monument *proto.AppMap_Monument
+}
+
+// String describes the event for chat, such as "Cargo Ship spawned at G14"
+func (e WorldEvent) String() string {
+	name := MarkerTypeName(e.Marker.Type)
+	if e.Type == WorldEventHeliDowned {
+		name = MarkerTypeName(proto.AppMarkerType_PatrolHelicopter)
+	}
+
+	action := map[WorldEventType]string{
+		WorldEventSpawned:    "spawned",
+		WorldEventDespawned:  "despawned",
+		WorldEventMoved:      "moved",
+		WorldEventHeliDowned: "was taken down",
+	}[e.Type]
+
+	switch {
+	case e.Monument != nil:
+		return fmt.Sprintf("%s %s at %s", name, action, MonumentName(e.Monument.GetToken()))
+	case e.Grid != "":
+		return fmt.Sprintf("%s %s at %s", name, action, e.Grid)
+	default:
+		return fmt.Sprintf("%s %s", name, action)
+	}
+}
+
+// WorldEventDetector diffs successive map marker snapshots into world events
+type WorldEventDetector struct {
+	// MoveThreshold is the distance a marker must move to emit WorldEventMoved
+	MoveThreshold float32
+	// MonumentRadius is how close a marker must be to a monument to be at it
+	MonumentRadius float32
+	// HeliDownedRadius is how close an explosion must be to where a patrol
+	// helicopter disappeared for it to count as downed
+	HeliDownedRadius float32
+	// HeliDownedWindow is how long after a patrol helicopter disappears an
+	// explosion can still count as it being downed
+	HeliDownedWindow time.Duration
+
+	grid      Grid
+	monuments []*proto.AppMap_Monument
+	previous  map[uint32]Marker
+	lostHelis []worldEventHeli
+	started   bool
+}
+
+// worldEventHeli is a patrol helicopter that disappeared
+type worldEventHeli struct {
+	marker Marker
+	time   time.Time
+}
+
+// NewWorldEventDetector creates a detector for a map of the given world size
+// with its monuments, as returned by GetInfo and GetMap
+func NewWorldEventDetector(mapSize uint32, monuments []*proto.AppMap_Monument) *WorldEventDetector {
+	return &WorldEventDetector{
+		MoveThreshold:    DefaultMoveThreshold,
+		MonumentRadius:   DefaultMonumentRadius,
+		HeliDownedRadius: DefaultHeliDownedRadius,
+		HeliDownedWindow: DefaultHeliDownedWindow,
+		grid:             NewGrid(mapSize),
+		monuments:        monuments,
+		previous:         make(map[uint32]Marker),
+	}
+}
+
+// Update compares the markers with the previous snapshot and returns the
+// world events between them. The first snapshot is only recorded, as every
+// marker would otherwise be reported as spawned.
+func (d *WorldEventDetector) Update(at time.Time, markers *MapMarkers) []WorldEvent {
+	current := make(map[uint32]Marker)
+	for _, marker := range markers.Events() {
+		current[marker.ID] = marker
+	}
+
+	previous := d.previous
+	d.previous = current
+
+	if !d.started {
+		d.started = true
+		return nil
+	}
+
+	var events []WorldEvent
+
+	for _, marker := range markers.Events() {
+		old, ok := previous[marker.ID]
+		if !ok {
+			if marker.Type == proto.AppMarkerType_Explosion {
+				if heli, ok := d.downedHeli(at, marker); ok {
+					events = append(events, d.event(WorldEventHeliDowned, at, marker, &heli))
+				}
+			}
+			events = append(events, d.event(WorldEventSpawned, at, marker, nil))
+			continue
+		}
+
+		if Distance(old.X, old.Y, marker.X, marker.Y) >= d.MoveThreshold {
+			events = append(events, d.event(WorldEventMoved, at, marker, &old))
+		}
+	}
+
+	for id, marker := range previous {
+		if _, ok := current[id]; ok {
+			continue
+		}
+
+		// A helicopter is downed if an explosion appears where it was, which
+		// may only happen in a later snapshot
+		if marker.Type == proto.AppMarkerType_PatrolHelicopter {
+			if explosion, ok := d.explosionNear(markers, previous, marker); ok {
+				heli := marker
+				events = append(events, d.event(WorldEventHeliDowned, at, explosion, &heli))
+			} else {
+				d.lostHelis = append(d.lostHelis, worldEventHeli{marker: marker, time: at})
+			}
+		}
+
+		events = append(events, d.event(WorldEventDespawned, at, marker, nil))
+	}
+
+	d.expireHelis(at)
+	return events
+}
+
+// downedHeli returns a helicopter that disappeared before the explosion
+// appeared near where it was last seen
+func (d *WorldEventDetector) downedHeli(at time.Time, explosion Marker) (Marker, bool) {
+	for i, heli := range d.lostHelis {
+		if at.Sub(heli.time) > d.HeliDownedWindow {
+			continue
+		}
+		if Distance(heli.marker.X, heli.marker.Y, explosion.X, explosion.Y) <= d.HeliDownedRadius {
+			d.lostHelis = append(d.lostHelis[:i:i], d.lostHelis[i+1:]...)
+			return heli.marker, true
+		}
+	}
+	return Marker{}, false
+}
+
+// explosionNear returns a new explosion near a helicopter that disappeared
+// in the same snapshot
+func (d *WorldEventDetector) explosionNear(markers *MapMarkers, previous map[uint32]Marker, heli Marker) (Marker, bool) {
+	for _, explosion := range markers.ByType(proto.AppMarkerType_Explosion) {
+		if _, ok := previous[explosion.ID]; ok {
+			continue
+		}
+		if Distance(heli.X, heli.Y, explosion.X, explosion.Y) <= d.HeliDownedRadius {
+			return explosion, true
+		}
+	}
+	return Marker{}, false
+}
+
+// expireHelis forgets helicopters that disappeared too long ago
+func (d *WorldEventDetector) expireHelis(at time.Time) {
+	kept := d.lostHelis[:0]
+	for _, heli := range d.lostHelis {
+		if at.Sub(heli.time) <= d.HeliDownedWindow {
+			kept = append(kept, heli)
+		}
+	}
+	d.lostHelis = kept
+}
+
+// event creates a world event with its position mapped to the grid and monuments
+func (d *WorldEventDetector) event(eventType WorldEventType, at time.Time, marker Marker, previous *Marker) WorldEvent {
+	event := WorldEvent{
+		Type:     eventType,
+		Time:     at,
+		Marker:   marker,
+		Previous: previous,
+		Grid:     d.grid.Reference(marker.X, marker.Y),
+	}
+
+	if monument, distance := NearestMonument(d.monuments, marker.X, marker.Y); monument != nil && distance <= d.MonumentRadius {
+		event.Monument = monument
+	}
+
+	return event
+}
+
+// WorldEventTracker polls the map markers and emits world events
+type WorldEventTracker struct {
+	client    *Client
+	detector  *WorldEventDetector
+	mutex     sync.Mutex
+	eventChan chan WorldEvent
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewWorldEventTracker creates a world event tracker for the client
+func NewWorldEventTracker(client *Client) *WorldEventTracker {
+	return &WorldEventTracker{
+		client:    client,
+		eventChan: make(chan WorldEvent, 100),
+	}
+}
+
+// Events returns a channel of world events
+func (t *WorldEventTracker) Events() <-chan WorldEvent {
+	return t.eventChan
+}
+
+// Start fetches the map size and monuments, then polls the map markers every
+// interval until Stop is called or ctx is done. An interval of zero uses
+// DefaultWorldEventPoll.
+func (t *WorldEventTracker) Start(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultWorldEventPoll
+	}
+
+	// Fetch without holding the lock, so Stop is not held up by a slow server
+	info, err := t.client.GetInfoContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get info: %w", err)
+	}
+
+	mapData, err := t.client.GetMapContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get map: %w", err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.cancel != nil {
+		return fmt.Errorf("world event tracker already started")
+	}
+
+	t.detector = NewWorldEventDetector(info.GetMapSize(), mapData.GetMonuments())
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	go t.poll(ctx, interval, t.detector, t.done)
+
+	return nil
+}
+
+// Stop stops polling
+func (t *WorldEventTracker) Stop() {
+	t.mutex.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	t.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// poll feeds the map markers to the detector until ctx is done
+func (t *WorldEventTracker) poll(ctx context.Context, interval time.Duration, detector *WorldEventDetector, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		markers, err := t.client.GetMapMarkersContext(WithPriority(ctx, PriorityBulk))
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Printf("Warning: World event poll failed: %v\n", err)
+			}
+		} else {
+			for _, event := range detector.Update(time.Now(), markers) {
+				select {
+				case t.eventChan <- event:
+					// Event sent successfully
+				default:
+					// Channel is full, log the error
+					fmt.Printf("Warning: World event channel is full, event %s dropped\n", event.Type)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
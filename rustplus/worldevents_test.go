@@ -0,0 +1,54 @@
+package rustplus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chickenfresh/go-rustplus/rustplus/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// markersOf creates map markers holding the given markers
+func markersOf(markers ...Marker) *MapMarkers {
+	return &MapMarkers{Markers: markers}
+}
+
+// TestWorldEventDetector tests detecting spawns, moves, despawns and downed helicopters
+func TestWorldEventDetector(t *testing.T) {
+	monuments := []*proto.AppMap_Monument{
+		{Token: protobuf.String("large_oil_rig"), X: protobuf.Float32(2900), Y: protobuf.Float32(100)},
+	}
+	detector := NewWorldEventDetector(3000, monuments)
+	start := time.Now()
+
+	heli := Marker{ID: 1, Type: proto.AppMarkerType_PatrolHelicopter, X: 1000, Y: 1000}
+	if events := detector.Update(start, markersOf(heli)); len(events) != 0 {
+		t.Fatalf("Expected no events for the first snapshot, got %+v", events)
+	}
+
+	cargo := Marker{ID: 2, Type: proto.AppMarkerType_CargoShip, X: 1500, Y: 1500}
+	crate := Marker{ID: 3, Type: proto.AppMarkerType_Crate, X: 2910, Y: 90}
+	heli.X += 100
+	events := detector.Update(start.Add(time.Minute), markersOf(heli, cargo, crate))
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %+v", events)
+	}
+	if events[0].Type != WorldEventMoved || events[1].String() != "Cargo Ship spawned at K10" {
+		t.Errorf("Unexpected events %s, %s", events[0].Type, events[1])
+	}
+	if events[2].String() != "Locked Crate spawned at Large Oil Rig" {
+		t.Errorf("Unexpected event %s", events[2])
+	}
+
+	// The helicopter disappears, then an explosion appears where it was
+	events = detector.Update(start.Add(2*time.Minute), markersOf(cargo, crate))
+	if len(events) != 1 || events[0].Type != WorldEventDespawned {
+		t.Fatalf("Expected the helicopter to despawn, got %+v", events)
+	}
+
+	explosion := Marker{ID: 4, Type: proto.AppMarkerType_Explosion, X: 1150, Y: 1000}
+	events = detector.Update(start.Add(3*time.Minute), markersOf(cargo, crate, explosion))
+	if len(events) != 2 || events[0].Type != WorldEventHeliDowned || events[0].Previous.ID != 1 {
+		t.Fatalf("Expected the helicopter to be downed, got %+v", events)
+	}
+}